@@ -0,0 +1,652 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanced/labeler"
+)
+
+const (
+	labelerPath     = "github.com/chanced/labeler"
+	generatedHeader = "// Code generated by labeler-gen. DO NOT EDIT."
+)
+
+var (
+	errUnsupportedType    = errors.New("unsupported type")
+	errUnexportedField    = errors.New("field must be exported")
+	errMultipleContainers = errors.New("only one container field is allowed per tag")
+	errContainerType      = errors.New("container must be a map[string]string")
+	errContainerInPtr     = errors.New("container can not be within a nested pointer")
+	errRecursiveType      = errors.New("recursive nested struct")
+	errConstraint         = errors.New("validation constraints (min, max, len, oneof, pattern, nonzero) are not supported")
+)
+
+// generate parses the package in dir and returns the formatted source
+// containing the generated methods for each of typeNames. Of opts, only the
+// tokens, Tag, Separator, AssignmentStr and NamingStrategy are used while
+// generating.
+func generate(dir string, typeNames []string, opts ...labeler.Option) ([]byte, error) {
+	g, err := newGenerator(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range typeNames {
+		if err := g.generateType(strings.TrimSpace(name)); err != nil {
+			return nil, err
+		}
+	}
+	return g.source()
+}
+
+type generator struct {
+	pkg     *types.Package
+	opts    labeler.Options
+	tagOpts []labeler.Option
+	ifaces  map[string]*types.Interface
+	imports map[string]string
+	buf     bytes.Buffer
+	vars    int
+	sets    []string
+}
+
+func newGenerator(dir string, opts []labeler.Option) (*generator, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if !isGenerated(f) {
+			files = append(files, f)
+		}
+	}
+	imp := importer.ForCompiler(fset, "source", nil)
+	conf := types.Config{
+		Importer: imp,
+		// errors are ignored as the package may reference methods that
+		// were previously generated.
+		Error: func(err error) {},
+	}
+	pkg, _ := conf.Check(bp.ImportPath, fset, files, nil)
+	lblPkg, err := imp.(types.ImporterFrom).ImportFrom(labelerPath, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{
+		pkg:     pkg,
+		opts:    labeler.NewOptions(opts...),
+		tagOpts: opts,
+		ifaces:  map[string]*types.Interface{"Stringer": newStringerInterface()},
+		imports: map[string]string{},
+	}
+	for _, name := range []string{
+		"Stringee", "TextMarshaler", "TextUnmarshaler",
+		"Marshaler", "MarshalerWithOpts", "Unmarshaler", "UnmarshalerWithOpts",
+		"Labeled", "GenericallyLabeled", "Labelee", "StrictLabelee", "GenericLabelee",
+	} {
+		g.ifaces[name] = lblPkg.Scope().Lookup(name).Type().Underlying().(*types.Interface)
+	}
+	return g, nil
+}
+
+func newStringerInterface() *types.Interface {
+	str := types.NewVar(token.NoPos, nil, "", types.Typ[types.String])
+	sig := types.NewSignature(nil, nil, types.NewTuple(str), false)
+	fn := types.NewFunc(token.NoPos, nil, "String", sig)
+	return types.NewInterfaceType([]*types.Func{fn}, nil).Complete()
+}
+
+func isGenerated(f *ast.File) bool {
+	for _, cg := range f.Comments {
+		if cg.Pos() > f.Package {
+			return false
+		}
+		for _, c := range cg.List {
+			if c.Text == generatedHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// implements reports whether t, or a pointer to t, implements the named
+// interface, mirroring the checks performed by labeler at runtime.
+func (g *generator) implements(t types.Type, iface string) bool {
+	it := g.ifaces[iface]
+	if types.Implements(t, it) {
+		return true
+	}
+	if _, ok := t.(*types.Pointer); ok {
+		return false
+	}
+	return types.Implements(types.NewPointer(t), it)
+}
+
+func (g *generator) addImport(path, name string) string {
+	g.imports[path] = name
+	return name
+}
+
+func (g *generator) qualifier(p *types.Package) string {
+	if p == g.pkg {
+		return ""
+	}
+	return g.addImport(p.Path(), p.Name())
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return prefix + strconv.Itoa(g.vars)
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) source() ([]byte, error) {
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "%s\n\npackage %s\n\n", generatedHeader, g.pkg.Name())
+	std, other := []string{}, []string{}
+	for path := range g.imports {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	src.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		src.WriteString("\n")
+	}
+	for _, path := range other {
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	src.WriteString(")\n\n")
+	src.Write(g.buf.Bytes())
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated source: %v\n%s", err, src.Bytes())
+	}
+	return out, nil
+}
+
+type typeModel struct {
+	name      string
+	typ       types.Type
+	root      *structModel
+	container *containerModel
+}
+
+type containerModel struct {
+	expr string
+	typ  types.Type
+	tag  *labeler.Tag
+}
+
+type structModel struct {
+	fields []*fieldModel
+}
+
+type fieldModel struct {
+	name   string
+	path   string
+	typ    types.Type
+	key    string
+	keys   []string
+	legacy string
+	tag    *labeler.Tag
+	nested *structModel
+	elem   types.Type
+	isPtr  bool
+}
+
+func (g *generator) generateType(name string) error {
+	obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return fmt.Errorf("type %s not found", name)
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return fmt.Errorf("%s is not a struct", name)
+	}
+	tm := &typeModel{name: name, typ: obj.Type()}
+	root, err := g.buildStruct(tm, st, "v", nil, false, map[types.Type]bool{obj.Type(): true})
+	if err != nil {
+		return fmt.Errorf("%s%w", name, err)
+	}
+	tm.root = root
+	g.addImport(labelerPath, "labeler")
+	g.printf("// GeneratedLabels indicates that %s has generated MarshalLabels and\n", name)
+	g.printf("// UnmarshalLabels methods.\n")
+	g.printf("func (*%s) GeneratedLabels() {}\n\n", name)
+	if err := g.generateMarshal(tm); err != nil {
+		return err
+	}
+	return g.generateUnmarshal(tm)
+}
+
+func (g *generator) buildStruct(tm *typeModel, st *types.Struct, expr string, prefix keyParts, inPtr bool, seen map[types.Type]bool) (*structModel, error) {
+	sm := &structModel{}
+	for i := 0; i < st.NumFields(); i++ {
+		fv := st.Field(i)
+		path := expr + "." + fv.Name()
+		tagStr, isTagged := reflect.StructTag(st.Tag(i)).Lookup(g.opts.Tag)
+		if strings.TrimSpace(tagStr) == "-" || (!isTagged && !fv.Exported()) {
+			continue
+		}
+		if !isTagged && g.opts.NamingStrategy != nil && g.supported(fv.Type()) {
+			isTagged, tagStr = true, g.opts.NamingStrategy(fv.Name())
+		}
+		if !isTagged {
+			f, err := g.buildNested(tm, fv, path, prefix, inPtr, seen)
+			if err != nil {
+				return nil, err
+			}
+			if f != nil && len(f.nested.fields) > 0 {
+				sm.fields = append(sm.fields, f)
+			}
+			continue
+		}
+		tag, err := labeler.ParseTag(tagStr, g.tagOpts...)
+		if err != nil {
+			return nil, fieldErr(path, err)
+		}
+		if !fv.Exported() {
+			return nil, fieldErr(path, errUnexportedField)
+		}
+		namespace := tag.KeyPrefix
+		switch {
+		case tag.Prefix && (namespace == "" || tag.IsContainer || !g.supported(fv.Type())):
+			if tag.IsContainer {
+				return nil, fieldErr(path, labeler.ErrInvalidPrefix)
+			}
+			f, err := g.buildNested(tm, fv, path, prefix.add(strconv.Quote(namespace+tag.Key), "o.PathDelimiter"), inPtr, seen)
+			if err != nil {
+				return nil, err
+			}
+			if f == nil {
+				return nil, fieldErr(path, labeler.ErrInvalidPrefix)
+			}
+			sm.fields = append(sm.fields, f)
+		case tag.IsContainer:
+			if err := g.setContainer(tm, fv.Type(), path, tag, inPtr); err != nil {
+				return nil, fieldErr(path, err)
+			}
+		case hasConstraints(tag):
+			return nil, fieldErr(path, errConstraint)
+		default:
+			f := &fieldModel{
+				name: fv.Name(),
+				path: strings.TrimPrefix(path, "v."),
+				typ:  fv.Type(),
+				tag:  tag,
+				key:  prefix.add(strconv.Quote(namespace + tag.Key)).expr(),
+			}
+			f.keys = []string{f.key}
+			for _, alias := range tag.Aliases {
+				f.keys = append(f.keys, prefix.add(strconv.Quote(namespace+alias)).expr())
+			}
+			if tag.Legacy != "" {
+				f.legacy = prefix.add(strconv.Quote(namespace + tag.Legacy)).expr()
+			}
+			sm.fields = append(sm.fields, f)
+		}
+	}
+	return sm, nil
+}
+
+// buildNested returns the model of fv if it is a struct or a pointer to a
+// struct and nil otherwise.
+func (g *generator) buildNested(tm *typeModel, fv *types.Var, path string, prefix keyParts, inPtr bool, seen map[types.Type]bool) (*fieldModel, error) {
+	elem := fv.Type()
+	ptr, isPtr := elem.(*types.Pointer)
+	if isPtr {
+		elem = ptr.Elem()
+	}
+	nst, ok := elem.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil
+	}
+	if seen[elem] {
+		return nil, fieldErr(path, errRecursiveType)
+	}
+	seen[elem] = true
+	nested, err := g.buildStruct(tm, nst, path, prefix, inPtr || isPtr, seen)
+	delete(seen, elem)
+	if err != nil {
+		return nil, err
+	}
+	return &fieldModel{name: fv.Name(), typ: fv.Type(), nested: nested, elem: elem, isPtr: isPtr}, nil
+}
+
+func (g *generator) setContainer(tm *typeModel, t types.Type, path string, tag *labeler.Tag, inPtr bool) error {
+	if tm.container != nil {
+		return errMultipleContainers
+	}
+	if inPtr {
+		return errContainerInPtr
+	}
+	m, ok := t.Underlying().(*types.Map)
+	if !ok || !isString(m.Key()) || !isString(m.Elem()) {
+		return errContainerType
+	}
+	tm.container = &containerModel{expr: path, typ: t, tag: tag}
+	return nil
+}
+
+// printContainerOptions prints the statement applying the options set on the
+// container's tag, if any, to o.
+func (g *generator) printContainerOptions(tm *typeModel) {
+	if tm.container == nil {
+		return
+	}
+	if lit := tagLiteral(tm.container.tag); lit != "" {
+		g.printf("o = o.FromTag(&labeler.Tag{%s})\n", lit)
+	}
+}
+
+// tagLiteral returns the fields of a composite literal for t, excluding the
+// key, or "" if t does not contain any options.
+func tagLiteral(t *labeler.Tag) string {
+	rv := reflect.ValueOf(t).Elem()
+	fields := []string{}
+	for i := 0; i < rv.NumField(); i++ {
+		name := rv.Type().Field(i).Name
+		fv := rv.Field(i)
+		if name == "Raw" || name == "Key" || name == "IsContainer" || fv.IsZero() {
+			continue
+		}
+		var v string
+		switch fv.Kind() {
+		case reflect.Bool:
+			v = strconv.FormatBool(fv.Bool())
+		case reflect.String:
+			v = strconv.Quote(fv.String())
+		case reflect.Uint8:
+			v = strconv.QuoteRune(rune(fv.Uint()))
+		case reflect.Int:
+			v = strconv.FormatInt(fv.Int(), 10)
+		default:
+			continue
+		}
+		fields = append(fields, name+": "+v)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// keyParts are the Go expressions which are concatenated to form a key.
+type keyParts []string
+
+func (k keyParts) add(parts ...string) keyParts {
+	return append(append(keyParts{}, k...), parts...)
+}
+
+// expr returns the expression for k, joining adjacent string literals.
+func (k keyParts) expr() string {
+	exprs := []string{}
+	for _, part := range k {
+		last := len(exprs) - 1
+		if last >= 0 && isQuoted(exprs[last]) && isQuoted(part) {
+			prev, _ := strconv.Unquote(exprs[last])
+			cur, _ := strconv.Unquote(part)
+			exprs[last] = strconv.Quote(prev + cur)
+			continue
+		}
+		exprs = append(exprs, part)
+	}
+	return strings.Join(exprs, " + ")
+}
+
+func isQuoted(s string) bool {
+	return strings.HasPrefix(s, "\"")
+}
+
+func fieldErr(path string, err error) error {
+	return fmt.Errorf("%s: %w", strings.TrimPrefix(path, "v"), err)
+}
+
+func isString(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.String
+}
+
+func isPkgType(t types.Type, pkg, name string) bool {
+	n, ok := t.(*types.Named)
+	if !ok || n.Obj().Pkg() == nil {
+		return false
+	}
+	return n.Obj().Pkg().Path() == pkg && n.Obj().Name() == name
+}
+
+// stdlibTypes are the types from the standard library, keyed by package
+// path, which labeler formats and parses directly.
+var stdlibTypes = map[string][]string{
+	"time":      {"Time", "Duration"},
+	"net":       {"IP", "IPNet"},
+	"net/url":   {"URL"},
+	"regexp":    {"Regexp"},
+	"math/big":  {"Int", "Float"},
+	"net/netip": {"Addr", "Prefix"},
+}
+
+func isStdlibType(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	if !ok || n.Obj().Pkg() == nil {
+		return false
+	}
+	for _, name := range stdlibTypes[n.Obj().Pkg().Path()] {
+		if n.Obj().Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func basicKind(t types.Type) (types.BasicKind, bool) {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return types.Invalid, false
+	}
+	switch b.Kind() {
+	case types.String, types.Bool,
+		types.Int, types.Int8, types.Int16, types.Int32, types.Int64,
+		types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64,
+		types.Float32, types.Float64, types.Complex64, types.Complex128:
+		return b.Kind(), true
+	}
+	return types.Invalid, false
+}
+
+func bitSize(k types.BasicKind) int {
+	switch k {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32, types.Float32:
+		return 32
+	case types.Int64, types.Uint64, types.Float64, types.Complex64:
+		return 64
+	case types.Complex128:
+		return 128
+	}
+	return 0
+}
+
+// collection returns the element type of t if t is a slice or array
+// that labeler treats as a collection.
+func (g *generator) collection(t types.Type) (types.Type, bool) {
+	if g.implements(t, "Stringee") || isStdlibType(t) {
+		return nil, false
+	}
+	switch ct := t.Underlying().(type) {
+	case *types.Slice:
+		return ct.Elem(), true
+	case *types.Array:
+		return ct.Elem(), true
+	}
+	return nil, false
+}
+
+// supported reports whether a field of type t can be both marshaled and
+// unmarshaled.
+func (g *generator) supported(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if elem, ok := g.collection(t); ok && g.canParse(elem) && g.canFormat(elem) {
+		return true
+	}
+	unmarshal := g.implements(t, "UnmarshalerWithOpts") || g.implements(t, "Unmarshaler") || g.canParse(t)
+	marshal := g.implements(t, "MarshalerWithOpts") || g.implements(t, "Marshaler") || g.canFormat(t)
+	return unmarshal && marshal
+}
+
+// options used by a field, resolved from its tag when set or from Options
+// otherwise.
+
+func boolExpr(isSet bool, v bool, opt string) string {
+	if isSet {
+		return strconv.FormatBool(v)
+	}
+	return opt
+}
+
+func ignoreCaseExpr(t *labeler.Tag) string {
+	return boolExpr(t.IgnoreCaseIsSet, t.IgnoreCase, "o.IgnoreCase")
+}
+
+func requiredExpr(t *labeler.Tag) string {
+	return boolExpr(t.RequiredIsSet, t.Required, "o.RequireAllFields")
+}
+
+func keepExpr(t *labeler.Tag) string {
+	return boolExpr(t.KeepIsSet, t.Keep, "o.KeepLabels")
+}
+
+// fieldErrorExpr returns an expression for the FieldError of f wrapping err,
+// matching the FieldErrors produced by labeler at runtime.
+func fieldErrorExpr(f *fieldModel, op string, key string, value string) string {
+	typ := types.TypeString(f.typ, func(p *types.Package) string { return p.Name() })
+	fields := []string{
+		fmt.Sprintf("Field: %q", f.name),
+		fmt.Sprintf("Path: %q", f.path),
+		"Key: " + key,
+	}
+	if value != "" {
+		fields = append(fields, "Value: "+value)
+	}
+	fields = append(fields, fmt.Sprintf("Type: %q", typ), "Op: "+op, "Err: err")
+	return "&labeler.FieldError{" + strings.Join(fields, ", ") + "}"
+}
+
+func replaceExpr(t *labeler.Tag) string {
+	return boolExpr(t.MergeIsSet, !t.Merge, "o.MergeMode == labeler.ReplaceCollections")
+}
+
+func omitEmptyExpr(t *labeler.Tag) string {
+	switch {
+	case t.OmitEmptyIsSet:
+		return "true"
+	case t.IncludeEmptyIsSet:
+		return "false"
+	}
+	return "o.OmitEmpty"
+}
+
+func splitExpr(t *labeler.Tag) string {
+	if s, ok := t.GetSplit(); ok {
+		return strconv.Quote(s)
+	}
+	return "o.Split"
+}
+
+func defaultExpr(t *labeler.Tag) string {
+	if t.DefaultIsSet {
+		return strconv.Quote(t.Default)
+	}
+	return "o.Default"
+}
+
+func intBaseExpr(t *labeler.Tag) string {
+	if b, ok := t.GetIntBase(); ok {
+		return strconv.Itoa(b)
+	}
+	return "o.IntBase"
+}
+
+func uintBaseExpr(t *labeler.Tag) string {
+	if b, ok := t.GetUintBase(); ok {
+		return strconv.Itoa(b)
+	}
+	return "o.UintBase"
+}
+
+func floatFormatExpr(t *labeler.Tag) string {
+	if f, ok := t.GetFloatFormat(); ok {
+		return strconv.QuoteRune(rune(f))
+	}
+	return "o.FloatFormat"
+}
+
+func complexFormatExpr(t *labeler.Tag) string {
+	if f, ok := t.GetComplexFormat(); ok {
+		return strconv.QuoteRune(rune(f))
+	}
+	return "o.ComplexFormat"
+}
+
+func timeFormatExpr(t *labeler.Tag) string {
+	if f, ok := t.GetTimeFormat(); ok {
+		return strconv.Quote(f)
+	}
+	return "o.TimeFormat"
+}
+
+// recv strips the dereference from x, if any, so that it can be used as the
+// receiver of a method call.
+func recv(x string) string {
+	if strings.HasPrefix(x, "(*") && strings.HasSuffix(x, ")") {
+		return x[2 : len(x)-1]
+	}
+	return x
+}
+
+// hasConstraints reports whether t has any validation constraints, which are
+// only checked by the runtime labeler.
+func hasConstraints(t *labeler.Tag) bool {
+	return t.Min != "" || t.Max != "" || t.Len != "" || t.OneOf != nil || t.Pattern != "" || t.NonZero
+}
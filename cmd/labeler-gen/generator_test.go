@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/chanced/labeler"
+)
+
+func TestGeneratedExampleIsCurrent(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	tests := []struct {
+		file  string
+		types []string
+		opts  []labeler.Option
+	}{
+		{"config_labels.go", []string{"Config", "Server", "Strict"}, nil},
+		{"env_labels.go", []string{"Env"}, []labeler.Option{labeler.OptNamingStrategy(labeler.ScreamingSnakeCase)}},
+	}
+	for _, test := range tests {
+		src, err := generate(dir, test.types, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		existing, err := ioutil.ReadFile(filepath.Join(dir, test.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(src, existing) {
+			t.Errorf("%s is out of date; run go generate ./...", test.file)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir := filepath.Join("testdata", "invalid")
+	tests := []struct {
+		typ string
+		err error
+	}{
+		{"Unexported", errUnexportedField},
+		{"BadContainer", errContainerType},
+		{"Unsupported", errUnsupportedType},
+		{"Constrained", errConstraint},
+	}
+	for _, test := range tests {
+		_, err := generate(dir, []string{test.typ})
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v, received %v", test.typ, test.err, err)
+		}
+	}
+	if _, err := generate(dir, []string{"Missing"}); err == nil {
+		t.Error("expected an error for a missing type")
+	}
+}
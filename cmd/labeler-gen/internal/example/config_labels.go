@@ -0,0 +1,1978 @@
+// Code generated by labeler-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"math/big"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chanced/labeler"
+)
+
+// GeneratedLabels indicates that Config has generated MarshalLabels and
+// UnmarshalLabels methods.
+func (*Config) GeneratedLabels() {}
+
+// MarshalLabels marshals v into labels.
+func (v *Config) MarshalLabels(o labeler.Options) (map[string]string, error) {
+	labels := make(map[string]string)
+	errs := []*labeler.FieldError{}
+	for key, value := range v.Labels {
+		if o.OmitEmpty && value == "" {
+			continue
+		}
+		labels[key] = value
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Name)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Name", Path: "Name", Key: "name", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["name"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = v.Color.String()
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Color", Path: "Color", Key: "color", Type: "example.Color", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["color"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatBool(bool(v.Enabled))
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Enabled", Path: "Enabled", Key: "enabled", Type: "bool", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["enabled"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatInt(int64(v.Count), o.IntBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Count", Path: "Count", Key: "count", Type: "int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["count"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatInt(int64(v.Mask), 2)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Mask", Path: "Mask", Key: "mask", Type: "int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["mask"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatUint(uint64(v.Mode), 8)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Mode", Path: "Mode", Key: "mode", Type: "uint16", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["mode"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatFloat(float64(v.Ratio), o.FloatFormat, -1, 64)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Ratio", Path: "Ratio", Key: "ratio", Type: "float64", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["ratio"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatFloat(float64(v.Exact), 'e', -1, 32)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Exact", Path: "Exact", Key: "exact", Type: "float32", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["exact"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatComplex(complex128(v.Complex), o.ComplexFormat, -1, 64)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Complex", Path: "Complex", Key: "complex", Type: "complex64", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["complex"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = v.Started.Format("2006-01-02")
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Started", Path: "Started", Key: "started", Type: "time.Time", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["started"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = v.Interval.String()
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Interval", Path: "Interval", Key: "interval", Type: "time.Duration", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["interval"] = s
+			}
+		}
+	}
+	{
+		var err error
+		strs := []string{}
+		for _, elem := range v.Tags {
+			var s string
+			s = string(elem)
+			if err != nil {
+				break
+			}
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				strs = append(strs, s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Tags", Path: "Tags", Key: "tags", Type: "[]string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			labels["tags"] = strings.Join(strs, o.Split)
+		}
+	}
+	{
+		var err error
+		strs := []string{}
+		for _, elem := range v.Ports {
+			var s string
+			s = strconv.FormatInt(int64(elem), o.IntBase)
+			if err != nil {
+				break
+			}
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				strs = append(strs, s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Ports", Path: "Ports", Key: "ports", Type: "[]int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			labels["ports"] = strings.Join(strs, "|")
+		}
+	}
+	{
+		var err error
+		strs := []string{}
+		for _, elem := range v.Pair {
+			var s string
+			s = string(elem)
+			if err != nil {
+				break
+			}
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				strs = append(strs, s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Pair", Path: "Pair", Key: "pair", Type: "[2]string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			labels["pair"] = strings.Join(strs, o.Split)
+		}
+	}
+	{
+		var err error
+		strs := []string{}
+		for _, elem := range v.Colors {
+			var s string
+			s = elem.String()
+			if err != nil {
+				break
+			}
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				strs = append(strs, s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Colors", Path: "Colors", Key: "colors", Type: "[]example.Color", Op: labeler.OpMarshal, Err: err})
+		} else {
+			labels["colors"] = strings.Join(strs, o.Split)
+		}
+	}
+	{
+		p1 := v.Limit
+		if p1 == nil {
+			p1 = new(int)
+		}
+		var s string
+		var err error
+		s = strconv.FormatInt(int64((*p1)), o.IntBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Limit", Path: "Limit", Key: "limit", Type: "*int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["limit"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Secret)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Secret", Path: "Secret", Key: "secret", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["secret"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Mixed)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Mixed", Path: "Mixed", Key: "Mixed", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["Mixed"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Region)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Region", Path: "Region", Key: "region", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = "us-east1"
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["region"] = s
+				labels["zone"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Empty)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Empty", Path: "Empty", Key: "empty", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			labels["empty"] = s
+		}
+	}
+	{
+		var s string
+		var err error
+		if len(v.IP) > 0 {
+			s = v.IP.String()
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "IP", Path: "IP", Key: "ip", Type: "net.IP", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["ip"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		if v.Network.IP != nil {
+			s = v.Network.String()
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Network", Path: "Network", Key: "network", Type: "net.IPNet", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["network"] = s
+			}
+		}
+	}
+	{
+		p2 := v.Endpoint
+		if p2 == nil {
+			p2 = new(url.URL)
+		}
+		var s string
+		var err error
+		s = p2.String()
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Endpoint", Path: "Endpoint", Key: "endpoint", Type: "*url.URL", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["endpoint"] = s
+			}
+		}
+	}
+	{
+		p3 := v.Pattern
+		if p3 == nil {
+			p3 = new(regexp.Regexp)
+		}
+		var s string
+		var err error
+		s = p3.String()
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Pattern", Path: "Pattern", Key: "pattern", Type: "*regexp.Regexp", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["pattern"] = s
+			}
+		}
+	}
+	{
+		p4 := v.Total
+		if p4 == nil {
+			p4 = new(big.Int)
+		}
+		var s string
+		var err error
+		s = p4.Text(o.IntBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Total", Path: "Total", Key: "total", Type: "*big.Int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["total"] = s
+			}
+		}
+	}
+	{
+		var err error
+		strs := []string{}
+		for _, elem := range v.Peers {
+			var s string
+			if len(elem) > 0 {
+				s = elem.String()
+			}
+			if err != nil {
+				break
+			}
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				strs = append(strs, s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Peers", Path: "Peers", Key: "peers", Type: "[]net.IP", Op: labeler.OpMarshal, Err: err})
+		} else {
+			labels["peers"] = strings.Join(strs, o.Split)
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Team)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Team", Path: "Team", Key: "example.com/team", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["example.com/team"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Database.Host)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Host", Path: "Database.Host", Key: "db_host", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = "localhost"
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["db_host"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatUint(uint64(v.Database.Port), o.UintBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Port", Path: "Database.Port", Key: "db_port", Type: "example.Port", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["db_port"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = v.Database.Timeout.String()
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Timeout", Path: "Database.Timeout", Key: "db_timeout", Type: "time.Duration", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["db_timeout"] = s
+			}
+		}
+	}
+	{
+		n5 := v.Cache
+		if n5 == nil {
+			n5 = new(Cache)
+		}
+		{
+			var s string
+			var err error
+			s = strconv.FormatInt(int64(n5.Size), o.IntBase)
+			if err != nil {
+				errs = append(errs, &labeler.FieldError{Field: "Size", Path: "Cache.Size", Key: "cache_size", Type: "int", Op: labeler.OpMarshal, Err: err})
+			} else {
+				if s == "" {
+					s = o.Default
+				}
+				if s != "" || !o.OmitEmpty {
+					labels["cache_size"] = s
+				}
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Primary.Host)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Host", Path: "Primary.Host", Key: "primary" + o.PathDelimiter + "db_host", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = "localhost"
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["primary"+o.PathDelimiter+"db_host"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatUint(uint64(v.Primary.Port), o.UintBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Port", Path: "Primary.Port", Key: "primary" + o.PathDelimiter + "db_port", Type: "example.Port", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["primary"+o.PathDelimiter+"db_port"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = v.Primary.Timeout.String()
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Timeout", Path: "Primary.Timeout", Key: "primary" + o.PathDelimiter + "db_timeout", Type: "time.Duration", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["primary"+o.PathDelimiter+"db_timeout"] = s
+			}
+		}
+	}
+	{
+		n6 := v.Replica
+		if n6 == nil {
+			n6 = new(Database)
+		}
+		{
+			var s string
+			var err error
+			s = string(n6.Host)
+			if err != nil {
+				errs = append(errs, &labeler.FieldError{Field: "Host", Path: "Replica.Host", Key: "replica" + o.PathDelimiter + "db_host", Type: "string", Op: labeler.OpMarshal, Err: err})
+			} else {
+				if s == "" {
+					s = "localhost"
+				}
+				if s != "" || !o.OmitEmpty {
+					labels["replica"+o.PathDelimiter+"db_host"] = s
+				}
+			}
+		}
+		{
+			var s string
+			var err error
+			s = strconv.FormatUint(uint64(n6.Port), o.UintBase)
+			if err != nil {
+				errs = append(errs, &labeler.FieldError{Field: "Port", Path: "Replica.Port", Key: "replica" + o.PathDelimiter + "db_port", Type: "example.Port", Op: labeler.OpMarshal, Err: err})
+			} else {
+				if s == "" {
+					s = o.Default
+				}
+				if s != "" || !o.OmitEmpty {
+					labels["replica"+o.PathDelimiter+"db_port"] = s
+				}
+			}
+		}
+		{
+			var s string
+			var err error
+			s = n6.Timeout.String()
+			if err != nil {
+				errs = append(errs, &labeler.FieldError{Field: "Timeout", Path: "Replica.Timeout", Key: "replica" + o.PathDelimiter + "db_timeout", Type: "time.Duration", Op: labeler.OpMarshal, Err: err})
+			} else {
+				if s == "" {
+					s = o.Default
+				}
+				if s != "" || !o.OmitEmpty {
+					labels["replica"+o.PathDelimiter+"db_timeout"] = s
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return labels, labeler.NewParsingError(errs)
+	}
+	return labels, nil
+}
+
+// UnmarshalLabels unmarshals labels into v.
+func (v *Config) UnmarshalLabels(m map[string]string, o labeler.Options) error {
+	labels := labeler.NewLabelSet(m)
+	errs := []*labeler.FieldError{}
+	{
+		var err error
+		s, ok := labels.Get("name", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				v.Name = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Name", Path: "Name", Key: "name", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Name = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("name")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("color", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				err = v.Color.FromString(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Color", Path: "Color", Key: "color", Value: s, Type: "example.Color", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero Color
+			v.Color = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("color")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("enabled", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseBool(s); e != nil {
+					err = e
+				} else {
+					v.Enabled = bool(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Enabled", Path: "Enabled", Key: "enabled", Value: s, Type: "bool", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero bool
+			v.Enabled = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("enabled")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("count", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseInt(s, o.IntBase, 0); e != nil {
+					err = e
+				} else {
+					v.Count = int(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Count", Path: "Count", Key: "count", Value: s, Type: "int", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero int
+			v.Count = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("count")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("mask", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseInt(s, 2, 0); e != nil {
+					err = e
+				} else {
+					v.Mask = int(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Mask", Path: "Mask", Key: "mask", Value: s, Type: "int", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero int
+			v.Mask = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("mask")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("mode", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseUint(s, 8, 16); e != nil {
+					err = e
+				} else {
+					v.Mode = uint16(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Mode", Path: "Mode", Key: "mode", Value: s, Type: "uint16", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero uint16
+			v.Mode = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("mode")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("ratio", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseFloat(s, 64); e != nil {
+					err = e
+				} else {
+					v.Ratio = float64(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Ratio", Path: "Ratio", Key: "ratio", Value: s, Type: "float64", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero float64
+			v.Ratio = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("ratio")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("exact", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseFloat(s, 32); e != nil {
+					err = e
+				} else {
+					v.Exact = float32(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Exact", Path: "Exact", Key: "exact", Value: s, Type: "float32", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero float32
+			v.Exact = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("exact")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("complex", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseComplex(s, 64); e != nil {
+					err = e
+				} else {
+					v.Complex = complex64(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Complex", Path: "Complex", Key: "complex", Value: s, Type: "complex64", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero complex64
+			v.Complex = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("complex")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("started", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if tv, e := time.Parse("2006-01-02", s); e != nil {
+					err = e
+				} else {
+					v.Started = tv
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Started", Path: "Started", Key: "started", Value: s, Type: "time.Time", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero time.Time
+			v.Started = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("started")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("interval", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if d, e := time.ParseDuration(s); e != nil {
+					err = e
+				} else {
+					v.Interval = d
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Interval", Path: "Interval", Key: "interval", Value: s, Type: "time.Duration", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero time.Duration
+			v.Interval = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("interval")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("tags", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if o.MergeMode == labeler.ReplaceCollections {
+					v.Tags = nil
+				}
+				for _, part := range strings.Split(s, o.Split) {
+					var elem string
+					elem = string(part)
+					if err != nil {
+						break
+					}
+					v.Tags = append(v.Tags, elem)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Tags", Path: "Tags", Key: "tags", Value: s, Type: "[]string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero []string
+			v.Tags = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("tags")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("ports", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if o.MergeMode == labeler.ReplaceCollections {
+					v.Ports = nil
+				}
+				for _, part := range strings.Split(s, "|") {
+					var elem int
+					if n, e := strconv.ParseInt(part, o.IntBase, 0); e != nil {
+						err = e
+					} else {
+						elem = int(n)
+					}
+					if err != nil {
+						break
+					}
+					v.Ports = append(v.Ports, elem)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Ports", Path: "Ports", Key: "ports", Value: s, Type: "[]int", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero []int
+			v.Ports = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("ports")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("pair", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if o.MergeMode == labeler.ReplaceCollections {
+					v.Pair = [2]string{}
+				}
+				for i, part := range strings.Split(s, o.Split) {
+					if i >= len(v.Pair) {
+						break
+					}
+					v.Pair[i] = string(part)
+					if err != nil {
+						break
+					}
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Pair", Path: "Pair", Key: "pair", Value: s, Type: "[2]string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero [2]string
+			v.Pair = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("pair")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("colors", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if o.MergeMode == labeler.ReplaceCollections {
+					v.Colors = nil
+				}
+				for _, part := range strings.Split(s, o.Split) {
+					var elem Color
+					err = elem.FromString(part)
+					if err != nil {
+						break
+					}
+					v.Colors = append(v.Colors, elem)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Colors", Path: "Colors", Key: "colors", Value: s, Type: "[]example.Color", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero []Color
+			v.Colors = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("colors")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("limit", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if v.Limit == nil {
+					v.Limit = new(int)
+				}
+				if n, e := strconv.ParseInt(s, o.IntBase, 0); e != nil {
+					err = e
+				} else {
+					(*v.Limit) = int(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Limit", Path: "Limit", Key: "limit", Value: s, Type: "*int", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero *int
+			v.Limit = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("limit")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("secret", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				v.Secret = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Secret", Path: "Secret", Key: "secret", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Secret = zero
+		}
+		labels.Delete("secret")
+	}
+	{
+		var err error
+		s, ok := labels.Get("Mixed", false)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				v.Mixed = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Mixed", Path: "Mixed", Key: "Mixed", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Mixed = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("Mixed")
+		}
+	}
+	{
+		var err error
+		keys := []string{"region", "zone", "location"}
+		s, i, ok := labels.Lookup(keys, o.IgnoreCase)
+		key := keys[0]
+		if i > 0 {
+			key = keys[i]
+		}
+		if i > 0 && o.AliasHandler != nil {
+			o.AliasHandler(labeler.AliasMatch{Field: "Region", Key: keys[0], Alias: keys[i]})
+		}
+		if !ok {
+			s, ok = "us-east1", true
+		}
+		if ok {
+			v.Region = string(s)
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Region", Path: "Region", Key: key, Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		}
+		if !o.KeepLabels {
+			for _, key := range keys {
+				labels.Delete(key)
+			}
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("empty", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			ok = true
+			if ok {
+				v.Empty = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Empty", Path: "Empty", Key: "empty", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Empty = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("empty")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("ip", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if ip := net.ParseIP(s); ip == nil {
+					err = &net.ParseError{Type: "IP address", Text: s}
+				} else {
+					v.IP = ip
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "IP", Path: "IP", Key: "ip", Value: s, Type: "net.IP", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero net.IP
+			v.IP = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("ip")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("network", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if _, n, e := net.ParseCIDR(s); e != nil {
+					err = e
+				} else {
+					v.Network = *n
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Network", Path: "Network", Key: "network", Value: s, Type: "net.IPNet", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero net.IPNet
+			v.Network = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("network")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("endpoint", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if v.Endpoint == nil {
+					v.Endpoint = new(url.URL)
+				}
+				if u, e := url.Parse(s); e != nil {
+					err = e
+				} else {
+					(*v.Endpoint) = *u
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Endpoint", Path: "Endpoint", Key: "endpoint", Value: s, Type: "*url.URL", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero *url.URL
+			v.Endpoint = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("endpoint")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("pattern", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if v.Pattern == nil {
+					v.Pattern = new(regexp.Regexp)
+				}
+				if re, e := regexp.Compile(s); e != nil {
+					err = e
+				} else {
+					(*v.Pattern) = *re
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Pattern", Path: "Pattern", Key: "pattern", Value: s, Type: "*regexp.Regexp", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero *regexp.Regexp
+			v.Pattern = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("pattern")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("total", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if v.Total == nil {
+					v.Total = new(big.Int)
+				}
+				if _, ok := v.Total.SetString(s, o.IntBase); !ok {
+					err = &strconv.NumError{Func: "SetString", Num: s, Err: strconv.ErrSyntax}
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Total", Path: "Total", Key: "total", Value: s, Type: "*big.Int", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero *big.Int
+			v.Total = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("total")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("peers", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if o.MergeMode == labeler.ReplaceCollections {
+					v.Peers = nil
+				}
+				for _, part := range strings.Split(s, o.Split) {
+					var elem net.IP
+					if ip := net.ParseIP(part); ip == nil {
+						err = &net.ParseError{Type: "IP address", Text: part}
+					} else {
+						elem = ip
+					}
+					if err != nil {
+						break
+					}
+					v.Peers = append(v.Peers, elem)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Peers", Path: "Peers", Key: "peers", Value: s, Type: "[]net.IP", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero []net.IP
+			v.Peers = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("peers")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("example.com/team", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				v.Team = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Team", Path: "Team", Key: "example.com/team", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Team = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("example.com/team")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("db_host", o.IgnoreCase)
+		if !ok {
+			s, ok = "localhost", true
+		}
+		if ok {
+			v.Database.Host = string(s)
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Host", Path: "Database.Host", Key: "db_host", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		}
+		if !o.KeepLabels {
+			labels.Delete("db_host")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("db_port", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseUint(s, o.UintBase, 16); e != nil {
+					err = e
+				} else {
+					v.Database.Port = Port(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Port", Path: "Database.Port", Key: "db_port", Value: s, Type: "example.Port", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero Port
+			v.Database.Port = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("db_port")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("db_timeout", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if d, e := time.ParseDuration(s); e != nil {
+					err = e
+				} else {
+					v.Database.Timeout = d
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Timeout", Path: "Database.Timeout", Key: "db_timeout", Value: s, Type: "time.Duration", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero time.Duration
+			v.Database.Timeout = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("db_timeout")
+		}
+	}
+	{
+		n7 := v.Cache
+		if n7 == nil {
+			n7 = new(Cache)
+		}
+		set8 := false
+		{
+			var err error
+			s, ok := labels.Get("cache_size", o.IgnoreCase)
+			found := ok
+			if !ok && o.RequireAllFields {
+				err = labeler.ErrLabelRequired
+			} else {
+				if !ok && !o.OmitEmpty {
+					ok = true
+				}
+				if ok {
+					set8 = true
+					if n, e := strconv.ParseInt(s, o.IntBase, 0); e != nil {
+						err = e
+					} else {
+						n7.Size = int(n)
+					}
+				}
+			}
+			if err != nil {
+				errs = append(errs, &labeler.FieldError{Field: "Size", Path: "Cache.Size", Key: "cache_size", Value: s, Type: "int", Op: labeler.OpUnmarshal, Err: err})
+			} else if !found && o.ResetMissing {
+				var zero int
+				n7.Size = zero
+			}
+			if !o.KeepLabels {
+				labels.Delete("cache_size")
+			}
+		}
+		if set8 {
+			v.Cache = n7
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("primary"+o.PathDelimiter+"db_host", o.IgnoreCase)
+		if !ok {
+			s, ok = "localhost", true
+		}
+		if ok {
+			v.Primary.Host = string(s)
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Host", Path: "Primary.Host", Key: "primary" + o.PathDelimiter + "db_host", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		}
+		if !o.KeepLabels {
+			labels.Delete("primary" + o.PathDelimiter + "db_host")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("primary"+o.PathDelimiter+"db_port", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseUint(s, o.UintBase, 16); e != nil {
+					err = e
+				} else {
+					v.Primary.Port = Port(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Port", Path: "Primary.Port", Key: "primary" + o.PathDelimiter + "db_port", Value: s, Type: "example.Port", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero Port
+			v.Primary.Port = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("primary" + o.PathDelimiter + "db_port")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("primary"+o.PathDelimiter+"db_timeout", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if d, e := time.ParseDuration(s); e != nil {
+					err = e
+				} else {
+					v.Primary.Timeout = d
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Timeout", Path: "Primary.Timeout", Key: "primary" + o.PathDelimiter + "db_timeout", Value: s, Type: "time.Duration", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero time.Duration
+			v.Primary.Timeout = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("primary" + o.PathDelimiter + "db_timeout")
+		}
+	}
+	{
+		n9 := v.Replica
+		if n9 == nil {
+			n9 = new(Database)
+		}
+		set10 := false
+		{
+			var err error
+			s, ok := labels.Get("replica"+o.PathDelimiter+"db_host", o.IgnoreCase)
+			if !ok {
+				s, ok = "localhost", true
+			}
+			if ok {
+				set10 = true
+				n9.Host = string(s)
+			}
+			if err != nil {
+				errs = append(errs, &labeler.FieldError{Field: "Host", Path: "Replica.Host", Key: "replica" + o.PathDelimiter + "db_host", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+			}
+			if !o.KeepLabels {
+				labels.Delete("replica" + o.PathDelimiter + "db_host")
+			}
+		}
+		{
+			var err error
+			s, ok := labels.Get("replica"+o.PathDelimiter+"db_port", o.IgnoreCase)
+			found := ok
+			if !ok && o.RequireAllFields {
+				err = labeler.ErrLabelRequired
+			} else {
+				if !ok && !o.OmitEmpty {
+					ok = true
+				}
+				if ok {
+					set10 = true
+					if n, e := strconv.ParseUint(s, o.UintBase, 16); e != nil {
+						err = e
+					} else {
+						n9.Port = Port(n)
+					}
+				}
+			}
+			if err != nil {
+				errs = append(errs, &labeler.FieldError{Field: "Port", Path: "Replica.Port", Key: "replica" + o.PathDelimiter + "db_port", Value: s, Type: "example.Port", Op: labeler.OpUnmarshal, Err: err})
+			} else if !found && o.ResetMissing {
+				var zero Port
+				n9.Port = zero
+			}
+			if !o.KeepLabels {
+				labels.Delete("replica" + o.PathDelimiter + "db_port")
+			}
+		}
+		{
+			var err error
+			s, ok := labels.Get("replica"+o.PathDelimiter+"db_timeout", o.IgnoreCase)
+			found := ok
+			if !ok && o.RequireAllFields {
+				err = labeler.ErrLabelRequired
+			} else {
+				if !ok && !o.OmitEmpty {
+					ok = true
+				}
+				if ok {
+					set10 = true
+					if d, e := time.ParseDuration(s); e != nil {
+						err = e
+					} else {
+						n9.Timeout = d
+					}
+				}
+			}
+			if err != nil {
+				errs = append(errs, &labeler.FieldError{Field: "Timeout", Path: "Replica.Timeout", Key: "replica" + o.PathDelimiter + "db_timeout", Value: s, Type: "time.Duration", Op: labeler.OpUnmarshal, Err: err})
+			} else if !found && o.ResetMissing {
+				var zero time.Duration
+				n9.Timeout = zero
+			}
+			if !o.KeepLabels {
+				labels.Delete("replica" + o.PathDelimiter + "db_timeout")
+			}
+		}
+		if set10 {
+			v.Replica = n9
+		}
+	}
+	if len(errs) > 0 {
+		return labeler.NewParsingError(errs)
+	}
+	if o.MergeMode == labeler.MergeCollections {
+		for k, s := range v.Labels {
+			if _, ok := labels.Get(k, false); !ok {
+				labels.Set(k, s)
+			}
+		}
+	}
+	v.Labels = labels.Map()
+	return nil
+}
+
+// GeneratedLabels indicates that Server has generated MarshalLabels and
+// UnmarshalLabels methods.
+func (*Server) GeneratedLabels() {}
+
+// MarshalLabels marshals v into labels.
+func (v *Server) MarshalLabels(o labeler.Options) (map[string]string, error) {
+	labels := make(map[string]string)
+	errs := []*labeler.FieldError{}
+	for key, value := range v.GetLabels() {
+		labels[key] = value
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Host)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Host", Path: "Host", Key: "host", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["host"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatInt(int64(v.Port), o.IntBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Port", Path: "Port", Key: "port", Type: "int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = "8080"
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["port"] = s
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return labels, labeler.NewParsingError(errs)
+	}
+	return labels, nil
+}
+
+// UnmarshalLabels unmarshals labels into v.
+func (v *Server) UnmarshalLabels(m map[string]string, o labeler.Options) error {
+	labels := labeler.NewLabelSet(m)
+	errs := []*labeler.FieldError{}
+	{
+		var err error
+		s, ok := labels.Get("host", o.IgnoreCase)
+		found := ok
+		if !ok {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				v.Host = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Host", Path: "Host", Key: "host", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Host = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("host")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("port", o.IgnoreCase)
+		if !ok {
+			s, ok = "8080", true
+		}
+		if ok {
+			if n, e := strconv.ParseInt(s, o.IntBase, 0); e != nil {
+				err = e
+			} else {
+				v.Port = int(n)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Port", Path: "Port", Key: "port", Value: s, Type: "int", Op: labeler.OpUnmarshal, Err: err})
+		}
+		if !o.KeepLabels {
+			labels.Delete("port")
+		}
+	}
+	if len(errs) > 0 {
+		return labeler.NewParsingError(errs)
+	}
+	v.SetLabels(labels.Map())
+	return nil
+}
+
+// GeneratedLabels indicates that Strict has generated MarshalLabels and
+// UnmarshalLabels methods.
+func (*Strict) GeneratedLabels() {}
+
+// MarshalLabels marshals v into labels.
+func (v *Strict) MarshalLabels(o labeler.Options) (map[string]string, error) {
+	labels := make(map[string]string)
+	errs := []*labeler.FieldError{}
+	o = o.FromTag(&labeler.Tag{Required: true, KeepIsSet: true, RequiredIsSet: true})
+	for key, value := range v.Labels {
+		if o.OmitEmpty && value == "" {
+			continue
+		}
+		labels[key] = value
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Name)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Name", Path: "Name", Key: "name", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["name"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatInt(int64(v.Count), o.IntBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Count", Path: "Count", Key: "count", Type: "int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["count"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Optional)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Optional", Path: "Optional", Key: "optional", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["optional"] = s
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return labels, labeler.NewParsingError(errs)
+	}
+	return labels, nil
+}
+
+// UnmarshalLabels unmarshals labels into v.
+func (v *Strict) UnmarshalLabels(m map[string]string, o labeler.Options) error {
+	labels := labeler.NewLabelSet(m)
+	errs := []*labeler.FieldError{}
+	o = o.FromTag(&labeler.Tag{Required: true, KeepIsSet: true, RequiredIsSet: true})
+	{
+		var err error
+		s, ok := labels.Get("name", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				v.Name = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Name", Path: "Name", Key: "name", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Name = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("name")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("count", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseInt(s, o.IntBase, 0); e != nil {
+					err = e
+				} else {
+					v.Count = int(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Count", Path: "Count", Key: "count", Value: s, Type: "int", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero int
+			v.Count = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("count")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("optional", o.IgnoreCase)
+		found := ok
+		if !ok && !o.OmitEmpty {
+			ok = true
+		}
+		if ok {
+			v.Optional = string(s)
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Optional", Path: "Optional", Key: "optional", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Optional = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("optional")
+		}
+	}
+	if len(errs) > 0 {
+		return labeler.NewParsingError(errs)
+	}
+	if o.MergeMode == labeler.MergeCollections {
+		for k, s := range v.Labels {
+			if _, ok := labels.Get(k, false); !ok {
+				labels.Set(k, s)
+			}
+		}
+	}
+	v.Labels = labels.Map()
+	return nil
+}
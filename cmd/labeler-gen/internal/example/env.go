@@ -0,0 +1,22 @@
+package example
+
+import "time"
+
+//go:generate go run ../.. -type=Env -naming=screaming -output=env_labels.go
+
+// Env derives its keys from its field names
+type Env struct {
+	ServerHost string
+	MaxConns   int
+	Timeout    time.Duration `label:"timeout_duration"`
+	Skipped    string        `label:"-"`
+	Tags       []string
+	Database   struct {
+		UserName string
+	}
+	Pool struct {
+		MaxIdle int
+	} `label:"pool,prefix"`
+	Callback func()
+	Labels   map[string]string `label:"*"`
+}
@@ -0,0 +1,312 @@
+// Code generated by labeler-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chanced/labeler"
+)
+
+// GeneratedLabels indicates that Env has generated MarshalLabels and
+// UnmarshalLabels methods.
+func (*Env) GeneratedLabels() {}
+
+// MarshalLabels marshals v into labels.
+func (v *Env) MarshalLabels(o labeler.Options) (map[string]string, error) {
+	labels := make(map[string]string)
+	errs := []*labeler.FieldError{}
+	for key, value := range v.Labels {
+		if o.OmitEmpty && value == "" {
+			continue
+		}
+		labels[key] = value
+	}
+	{
+		var s string
+		var err error
+		s = string(v.ServerHost)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "ServerHost", Path: "ServerHost", Key: "SERVER_HOST", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["SERVER_HOST"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatInt(int64(v.MaxConns), o.IntBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "MaxConns", Path: "MaxConns", Key: "MAX_CONNS", Type: "int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["MAX_CONNS"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = v.Timeout.String()
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Timeout", Path: "Timeout", Key: "timeout_duration", Type: "time.Duration", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["timeout_duration"] = s
+			}
+		}
+	}
+	{
+		var err error
+		strs := []string{}
+		for _, elem := range v.Tags {
+			var s string
+			s = string(elem)
+			if err != nil {
+				break
+			}
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				strs = append(strs, s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Tags", Path: "Tags", Key: "TAGS", Type: "[]string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			labels["TAGS"] = strings.Join(strs, o.Split)
+		}
+	}
+	{
+		var s string
+		var err error
+		s = string(v.Database.UserName)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "UserName", Path: "Database.UserName", Key: "USER_NAME", Type: "string", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["USER_NAME"] = s
+			}
+		}
+	}
+	{
+		var s string
+		var err error
+		s = strconv.FormatInt(int64(v.Pool.MaxIdle), o.IntBase)
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "MaxIdle", Path: "Pool.MaxIdle", Key: "pool" + o.PathDelimiter + "MAX_IDLE", Type: "int", Op: labeler.OpMarshal, Err: err})
+		} else {
+			if s == "" {
+				s = o.Default
+			}
+			if s != "" || !o.OmitEmpty {
+				labels["pool"+o.PathDelimiter+"MAX_IDLE"] = s
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return labels, labeler.NewParsingError(errs)
+	}
+	return labels, nil
+}
+
+// UnmarshalLabels unmarshals labels into v.
+func (v *Env) UnmarshalLabels(m map[string]string, o labeler.Options) error {
+	labels := labeler.NewLabelSet(m)
+	errs := []*labeler.FieldError{}
+	{
+		var err error
+		s, ok := labels.Get("SERVER_HOST", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				v.ServerHost = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "ServerHost", Path: "ServerHost", Key: "SERVER_HOST", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.ServerHost = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("SERVER_HOST")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("MAX_CONNS", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseInt(s, o.IntBase, 0); e != nil {
+					err = e
+				} else {
+					v.MaxConns = int(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "MaxConns", Path: "MaxConns", Key: "MAX_CONNS", Value: s, Type: "int", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero int
+			v.MaxConns = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("MAX_CONNS")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("timeout_duration", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if d, e := time.ParseDuration(s); e != nil {
+					err = e
+				} else {
+					v.Timeout = d
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Timeout", Path: "Timeout", Key: "timeout_duration", Value: s, Type: "time.Duration", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero time.Duration
+			v.Timeout = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("timeout_duration")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("TAGS", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if o.MergeMode == labeler.ReplaceCollections {
+					v.Tags = nil
+				}
+				for _, part := range strings.Split(s, o.Split) {
+					var elem string
+					elem = string(part)
+					if err != nil {
+						break
+					}
+					v.Tags = append(v.Tags, elem)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "Tags", Path: "Tags", Key: "TAGS", Value: s, Type: "[]string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero []string
+			v.Tags = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("TAGS")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("USER_NAME", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				v.Database.UserName = string(s)
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "UserName", Path: "Database.UserName", Key: "USER_NAME", Value: s, Type: "string", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero string
+			v.Database.UserName = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("USER_NAME")
+		}
+	}
+	{
+		var err error
+		s, ok := labels.Get("pool"+o.PathDelimiter+"MAX_IDLE", o.IgnoreCase)
+		found := ok
+		if !ok && o.RequireAllFields {
+			err = labeler.ErrLabelRequired
+		} else {
+			if !ok && !o.OmitEmpty {
+				ok = true
+			}
+			if ok {
+				if n, e := strconv.ParseInt(s, o.IntBase, 0); e != nil {
+					err = e
+				} else {
+					v.Pool.MaxIdle = int(n)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, &labeler.FieldError{Field: "MaxIdle", Path: "Pool.MaxIdle", Key: "pool" + o.PathDelimiter + "MAX_IDLE", Value: s, Type: "int", Op: labeler.OpUnmarshal, Err: err})
+		} else if !found && o.ResetMissing {
+			var zero int
+			v.Pool.MaxIdle = zero
+		}
+		if !o.KeepLabels {
+			labels.Delete("pool" + o.PathDelimiter + "MAX_IDLE")
+		}
+	}
+	if len(errs) > 0 {
+		return labeler.NewParsingError(errs)
+	}
+	if o.MergeMode == labeler.MergeCollections {
+		for k, s := range v.Labels {
+			if _, ok := labels.Get(k, false); !ok {
+				labels.Set(k, s)
+			}
+		}
+	}
+	v.Labels = labels.Map()
+	return nil
+}
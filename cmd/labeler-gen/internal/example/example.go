@@ -0,0 +1,125 @@
+// Package example contains types used to verify that the code generated by
+// labeler-gen matches the behavior of labeler.
+package example
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+//go:generate go run ../.. -type=Config,Server,Strict
+
+// Color is an enum implementing fmt.Stringer and labeler.Stringee
+type Color int
+
+// Colors
+const (
+	ColorUnknown Color = iota
+	ColorRed
+	ColorBlue
+)
+
+var colorToStr = map[Color]string{
+	ColorUnknown: "Unknown",
+	ColorRed:     "Red",
+	ColorBlue:    "Blue",
+}
+
+// ErrInvalidColor is returned from FromString when the color is unknown
+var ErrInvalidColor = errors.New("invalid color")
+
+func (c *Color) String() string {
+	return colorToStr[*c]
+}
+
+// FromString parses s into c
+func (c *Color) FromString(s string) error {
+	for k, v := range colorToStr {
+		if v == s {
+			*c = k
+			return nil
+		}
+	}
+	return ErrInvalidColor
+}
+
+// Port is a named numeric type
+type Port uint16
+
+// Database is nested within Config
+type Database struct {
+	Host    string        `label:"db_host,default:localhost"`
+	Port    Port          `label:"db_port"`
+	Timeout time.Duration `label:"db_timeout"`
+}
+
+// Cache is nested within Config by pointer
+type Cache struct {
+	Size int `label:"cache_size"`
+}
+
+// Config exercises the tokens supported by labeler-gen
+type Config struct {
+	Name      string         `label:"name"`
+	Color     Color          `label:"color"`
+	Enabled   bool           `label:"enabled"`
+	Count     int            `label:"count"`
+	Mask      int            `label:"mask,base:2"`
+	Mode      uint16         `label:"mode,uintbase:8"`
+	Ratio     float64        `label:"ratio"`
+	Exact     float32        `label:"exact,format:e"`
+	Complex   complex64      `label:"complex"`
+	Started   time.Time      `label:"started,format:2006-01-02"`
+	Interval  time.Duration  `label:"interval"`
+	Tags      []string       `label:"tags"`
+	Ports     []int          `label:"ports,split:|"`
+	Pair      [2]string      `label:"pair"`
+	Colors    []Color        `label:"colors"`
+	Limit     *int           `label:"limit"`
+	Secret    string         `label:"secret,discard"`
+	Mixed     string         `label:"Mixed,casesensitive"`
+	Region    string         `label:"region,default:us-east1,alias:zone|location,legacy:zone"`
+	Empty     string         `label:"empty,includeempty"`
+	IP        net.IP         `label:"ip"`
+	Network   net.IPNet      `label:"network"`
+	Endpoint  *url.URL       `label:"endpoint"`
+	Pattern   *regexp.Regexp `label:"pattern"`
+	Total     *big.Int       `label:"total"`
+	Peers     []net.IP       `label:"peers"`
+	Team      string         `label:"team,prefix:example.com/"`
+	Database  Database
+	Cache     *Cache
+	Primary   Database          `label:"primary,prefix"`
+	Replica   *Database         `label:"replica,prefix"`
+	Labels    map[string]string `label:"*"`
+	unlabeled string
+}
+
+// Server has no container and instead implements Labelee and Labeled
+type Server struct {
+	Host   string `label:"host,required"`
+	Port   int    `label:"port,default:8080"`
+	labels map[string]string
+}
+
+// SetLabels sets s's labels
+func (s *Server) SetLabels(labels map[string]string) {
+	s.labels = labels
+}
+
+// GetLabels returns s's labels
+func (s *Server) GetLabels() map[string]string {
+	return s.labels
+}
+
+// Strict requires all of its fields through the container tag
+type Strict struct {
+	Name     string            `label:"name"`
+	Count    int               `label:"count"`
+	Optional string            `label:"optional,notrequired"`
+	Labels   map[string]string `label:"*,required,discard"`
+}
@@ -0,0 +1,268 @@
+package example
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chanced/labeler"
+)
+
+// runtimeConfig shares Config's fields but not its generated methods so that
+// it is handled by labeler through reflection.
+type runtimeConfig Config
+
+var input = map[string]string{
+	"name":             "example",
+	"color":            "Blue",
+	"enabled":          "true",
+	"count":            "-42",
+	"mask":             "1011",
+	"mode":             "755",
+	"ratio":            "0.25",
+	"exact":            "1.5e+00",
+	"complex":          "(1+2i)",
+	"started":          "2020-01-02",
+	"interval":         "1m30s",
+	"tags":             "a,b,c",
+	"ports":            "80|443",
+	"pair":             "x,y",
+	"colors":           "Red,Blue",
+	"limit":            "7",
+	"secret":           "shh",
+	"mixed":            "lowercase",
+	"Mixed":            "Titlecase",
+	"db_host":          "db.example.com",
+	"db_port":          "5432",
+	"db_timeout":       "5s",
+	"cache_size":       "128",
+	"primary.db_host":  "primary.example.com",
+	"replica.db_port":  "6543",
+	"extra":            "value",
+	"location":         "us-west1",
+	"ip":               "10.0.0.1",
+	"network":          "192.168.0.0/16",
+	"endpoint":         "https://example.com/api",
+	"pattern":          "^[a-z]+$",
+	"total":            "123456789012345678901234567890",
+	"peers":            "10.0.0.2,::1",
+	"example.com/team": "platform",
+}
+
+func TestGeneratedUnmarshalMatchesRuntime(t *testing.T) {
+	gen := Config{}
+	if err := labeler.Unmarshal(input, &gen); err != nil {
+		t.Fatalf("generated: %v", err)
+	}
+	rt := runtimeConfig{}
+	if err := labeler.Unmarshal(input, &rt); err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	if !reflect.DeepEqual(Config(rt), gen) {
+		t.Errorf("expected generated to match runtime\nruntime:   %+v\ngenerated: %+v", rt, gen)
+	}
+	if gen.Primary.Host != "primary.example.com" || gen.Replica == nil || gen.Replica.Port != 6543 {
+		t.Errorf("expected prefixed fields to be set, was %+v %+v", gen.Primary, gen.Replica)
+	}
+	if gen.Cache == nil || gen.Cache.Size != 128 {
+		t.Errorf("expected Cache.Size to be 128, was %+v", gen.Cache)
+	}
+	if gen.Team != "platform" {
+		t.Errorf("expected Team to be set from its namespaced key, was %q", gen.Team)
+	}
+	if gen.Region != "us-west1" {
+		t.Errorf("expected Region to be set from its alias, was %q", gen.Region)
+	}
+	if _, ok := gen.Labels["secret"]; ok {
+		t.Error("expected secret to be discarded")
+	}
+}
+
+func TestGeneratedMarshalMatchesRuntime(t *testing.T) {
+	gen := Config{}
+	if err := labeler.Unmarshal(input, &gen); err != nil {
+		t.Fatal(err)
+	}
+	rt := runtimeConfig(gen)
+	genRes, err := labeler.Marshal(&gen)
+	if err != nil {
+		t.Fatalf("generated: %v", err)
+	}
+	rtRes, err := labeler.Marshal(&rt)
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	if !reflect.DeepEqual(rtRes, genRes) {
+		t.Errorf("expected generated to match runtime\nruntime:   %v\ngenerated: %v", rtRes, genRes)
+	}
+}
+
+func TestGeneratedInvalidFields(t *testing.T) {
+	gen := Config{}
+	err := labeler.Unmarshal(map[string]string{"color": "Green", "count": "x"}, &gen)
+	var pErr *labeler.ParsingError
+	if !errors.As(err, &pErr) {
+		t.Fatalf("expected ParsingError, received %v", err)
+	}
+	if len(pErr.Errors) != 2 {
+		t.Fatalf("expected 2 errors, received %d", len(pErr.Errors))
+	}
+	if pErr.Errors[0].Field != "Color" || !errors.Is(pErr.Errors[0], ErrInvalidColor) {
+		t.Errorf("expected first error to be for Color, was %v", pErr.Errors[0])
+	}
+
+	input := map[string]string{"db_port": "x", "primary.db_port": "y", "count": "z"}
+	genErr := labeler.Unmarshal(input, &Config{})
+	rt := runtimeConfig{}
+	rtErr := labeler.Unmarshal(input, &rt)
+	var genPErr, rtPErr *labeler.ParsingError
+	if !errors.As(genErr, &genPErr) || !errors.As(rtErr, &rtPErr) || len(genPErr.Errors) != len(rtPErr.Errors) {
+		t.Fatalf("expected errors to match\nruntime:   %v\ngenerated: %v", rtErr, genErr)
+	}
+	for i, ge := range genPErr.Errors {
+		re := rtPErr.Errors[i]
+		if ge.Field != re.Field || ge.Path != re.Path || ge.Key != re.Key || ge.Value != re.Value || ge.Type != re.Type || ge.Op != re.Op {
+			t.Errorf("expected errors to match\nruntime:   %+v\ngenerated: %+v", re, ge)
+		}
+	}
+}
+
+func TestGeneratedUnknownKeys(t *testing.T) {
+	input := map[string]string{"name": "example", "db_host": "db", "primary.db_port": "1", "intervl": "1s"}
+	opt := labeler.OptDisallowUnknownKeys()
+	genErr := labeler.Unmarshal(input, &Config{}, opt)
+	rtErr := labeler.Unmarshal(input, &runtimeConfig{}, opt)
+	if genErr == nil || rtErr == nil || genErr.Error() != rtErr.Error() {
+		t.Fatalf("expected errors to match\nruntime:   %v\ngenerated: %v", rtErr, genErr)
+	}
+	var ukErr *labeler.UnknownKeysError
+	if !errors.As(genErr, &ukErr) || len(ukErr.Keys) != 1 || ukErr.Keys[0].Key != "intervl" || ukErr.Keys[0].Suggestion != "interval" {
+		t.Errorf("expected intervl to be unknown, received %v", genErr)
+	}
+	delete(input, "intervl")
+	if err := labeler.Unmarshal(input, &Config{}, opt); err != nil {
+		t.Errorf("expected no error, received %v", err)
+	}
+}
+
+func TestGeneratedLabelee(t *testing.T) {
+	s := Server{}
+	if err := labeler.Unmarshal(map[string]string{"host": "localhost", "other": "x"}, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Host != "localhost" || s.Port != 8080 {
+		t.Errorf("unexpected values: %+v", s)
+	}
+	if s.labels["other"] != "x" {
+		t.Errorf("expected other to be set on labels, got %v", s.labels)
+	}
+	res, err := labeler.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res["host"] != "localhost" || res["port"] != "8080" || res["other"] != "x" {
+		t.Errorf("unexpected labels: %v", res)
+	}
+}
+
+type runtimeStrict Strict
+
+func TestGeneratedRequired(t *testing.T) {
+	input := map[string]string{"count": "1", "other": "x"}
+	gen := Strict{}
+	genErr := labeler.Unmarshal(input, &gen)
+	rt := runtimeStrict{}
+	rtErr := labeler.Unmarshal(input, &rt)
+	if !reflect.DeepEqual(Strict(rt), gen) {
+		t.Errorf("expected generated to match runtime\nruntime:   %+v\ngenerated: %+v", rt, gen)
+	}
+	if genErr == nil || rtErr == nil || genErr.Error() != rtErr.Error() {
+		t.Errorf("expected errors to match\nruntime:   %v\ngenerated: %v", rtErr, genErr)
+	}
+	var pErr *labeler.ParsingError
+	if !errors.As(genErr, &pErr) || len(pErr.Errors) != 1 || !errors.Is(pErr.Errors[0], labeler.ErrLabelRequired) {
+		t.Errorf("expected Name to be required, received %v", genErr)
+	}
+
+	input["name"] = "strict"
+	gen = Strict{}
+	if err := labeler.Unmarshal(input, &gen); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := gen.Labels["count"]; ok {
+		t.Error("expected count to be discarded")
+	}
+	if err := labeler.Unmarshal(map[string]string{}, &Server{}); !errors.As(err, new(*labeler.ParsingError)) {
+		t.Errorf("expected host to be required, received %v", err)
+	}
+}
+
+type runtimeEnv Env
+
+func TestGeneratedNamingMatchesRuntime(t *testing.T) {
+	input := map[string]string{
+		"SERVER_HOST":      "localhost",
+		"MAX_CONNS":        "12",
+		"timeout_duration": "3s",
+		"SKIPPED":          "skipped",
+		"TAGS":             "a,b",
+		"USER_NAME":        "admin",
+		"pool_MAX_IDLE":    "4",
+	}
+	opts := []labeler.Option{labeler.OptNamingStrategy(labeler.ScreamingSnakeCase), labeler.OptPathDelimiter("_")}
+	gen := Env{}
+	if err := labeler.Unmarshal(input, &gen, opts...); err != nil {
+		t.Fatalf("generated: %v", err)
+	}
+	rt := runtimeEnv{}
+	if err := labeler.Unmarshal(input, &rt, opts...); err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	if !reflect.DeepEqual(Env(rt), gen) {
+		t.Errorf("expected generated to match runtime\nruntime:   %+v\ngenerated: %+v", rt, gen)
+	}
+	if gen.Pool.MaxIdle != 4 || gen.Database.UserName != "admin" || gen.Skipped != "" {
+		t.Errorf("unexpected values: %+v", gen)
+	}
+	genRes, err := labeler.Marshal(&gen, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rtRes, err := labeler.Marshal(&rt, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rtRes, genRes) {
+		t.Errorf("expected generated to match runtime\nruntime:   %v\ngenerated: %v", rtRes, genRes)
+	}
+}
+
+func TestGeneratedMergeModesMatchRuntime(t *testing.T) {
+	input := map[string]string{"tags": "b,c", "pair": "z", "extra": "value"}
+	existing := func() Config {
+		return Config{
+			Name:   "existing",
+			Count:  5,
+			Tags:   []string{"a"},
+			Pair:   [2]string{"x", "y"},
+			Labels: map[string]string{"old": "x"},
+		}
+	}
+	for name, opts := range map[string][]labeler.Option{
+		"default": nil,
+		"merge":   {labeler.OptMerge()},
+		"replace": {labeler.OptReplace(), labeler.OptResetMissing()},
+	} {
+		gen := existing()
+		if err := labeler.Unmarshal(input, &gen, opts...); err != nil {
+			t.Fatalf("%s generated: %v", name, err)
+		}
+		rt := runtimeConfig(existing())
+		if err := labeler.Unmarshal(input, &rt, opts...); err != nil {
+			t.Fatalf("%s runtime: %v", name, err)
+		}
+		if !reflect.DeepEqual(Config(rt), gen) {
+			t.Errorf("%s: expected generated to match runtime\nruntime:   %+v\ngenerated: %+v", name, rt, gen)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Command labeler-gen generates MarshalLabels and UnmarshalLabels methods for
+// structs tagged for use with github.com/chanced/labeler. The generated methods
+// satisfy labeler.MarshalerWithOpts and labeler.UnmarshalerWithOpts and do not
+// rely on reflection.
+//
+// Usage:
+//
+//	//go:generate labeler-gen -type=Config,Server
+//
+// Flags:
+//
+//	-type    comma separated list of struct type names; required
+//	-output  output file name; default: <first type>_labels.go
+//	-tag     struct tag to generate for; default: "label"
+//	-naming  naming strategy used to derive keys for untagged fields:
+//	         snake, kebab, screaming or camel; default: none
+//
+// Tokens are read with labeler's default Options (aside from Tag and
+// NamingStrategy). The ContainerField option is not supported; use the
+// container token instead.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chanced/labeler"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma separated list of struct type names; required")
+	output    = flag.String("output", "", "output file name; default: <first type>_labels.go")
+	tag       = flag.String("tag", "label", "struct tag to generate for")
+	naming    = flag.String("naming", "", "naming strategy used to derive keys for untagged fields: snake, kebab, screaming or camel")
+)
+
+var namingStrategies = map[string]labeler.NamingStrategy{
+	"snake":     labeler.SnakeCase,
+	"kebab":     labeler.KebabCase,
+	"screaming": labeler.ScreamingSnakeCase,
+	"camel":     labeler.LowerCamelCase,
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of labeler-gen:\n")
+	fmt.Fprintf(os.Stderr, "\tlabeler-gen -type T [directory]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	opts := []labeler.Option{labeler.OptTag(*tag)}
+	if *naming != "" {
+		strategy, ok := namingStrategies[*naming]
+		if !ok {
+			flag.Usage()
+			os.Exit(2)
+		}
+		opts = append(opts, labeler.OptNamingStrategy(strategy))
+	}
+	types := strings.Split(*typeNames, ",")
+	src, err := generate(dir, types, opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "labeler-gen: %v\n", err)
+		os.Exit(1)
+	}
+	name := *output
+	if name == "" {
+		name = strings.ToLower(types[0]) + "_labels.go"
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	if err := ioutil.WriteFile(name, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "labeler-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+
+	"github.com/chanced/labeler"
+)
+
+func (g *generator) generateMarshal(tm *typeModel) error {
+	g.printf("// MarshalLabels marshals v into labels.\n")
+	g.printf("func (v *%s) MarshalLabels(o labeler.Options) (map[string]string, error) {\n", tm.name)
+	g.printf("labels := make(map[string]string)\n")
+	g.printf("errs := []*labeler.FieldError{}\n")
+	g.printContainerOptions(tm)
+	g.marshalContainer(tm)
+	if err := g.marshalStruct(tm.root, "v"); err != nil {
+		return fmt.Errorf("%s%w", tm.name, err)
+	}
+	g.printf("if len(errs) > 0 {\nreturn labels, labeler.NewParsingError(errs)\n}\n")
+	g.printf("return labels, nil\n}\n\n")
+	return nil
+}
+
+// marshalContainer adds the labels from the container first so that tagged
+// fields take precedence.
+func (g *generator) marshalContainer(tm *typeModel) {
+	switch {
+	case tm.container != nil:
+		g.printf("for key, value := range %s {\n", tm.container.expr)
+		g.printf("if o.OmitEmpty && value == \"\" {\ncontinue\n}\n")
+		g.printf("labels[key] = value\n}\n")
+	case g.implements(tm.typ, "GenericallyLabeled"):
+		g.printf("for key, value := range v.GetLabels(o.Tag) {\nlabels[key] = value\n}\n")
+	case g.implements(tm.typ, "Labeled"):
+		g.printf("for key, value := range v.GetLabels() {\nlabels[key] = value\n}\n")
+	}
+}
+
+func (g *generator) marshalStruct(sm *structModel, recv string) error {
+	for _, f := range sm.fields {
+		x := recv + "." + f.name
+		if f.nested == nil {
+			if err := g.marshalField(f, x); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.isPtr {
+			if err := g.marshalStruct(f.nested, x); err != nil {
+				return err
+			}
+			continue
+		}
+		n := g.newVar("n")
+		g.printf("{\n%s := %s\n", n, x)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", n, n, g.typeString(f.elem))
+		if err := g.marshalStruct(f.nested, n); err != nil {
+			return err
+		}
+		g.printf("}\n")
+	}
+	return nil
+}
+
+func (g *generator) marshalField(f *fieldModel, x string) error {
+	t := f.tag
+	key := f.key
+	appendErr := fmt.Sprintf("errs = append(errs, %s)\n", fieldErrorExpr(f, "labeler.OpMarshal", key, ""))
+	g.printf("{\n")
+	defer g.printf("}\n")
+
+	if elem, ok := g.collection(f.typ); ok && g.canFormat(elem) {
+		g.printf("var err error\n")
+		g.printf("strs := []string{}\n")
+		g.printf("for _, elem := range %s {\n", x)
+		g.printf("var s string\n")
+		if err := g.format(elem, "elem", "s", t); err != nil {
+			return fmt.Errorf(".%s: %w", f.name, err)
+		}
+		g.printf("if err != nil {\nbreak\n}\n")
+		g.printf("if s == \"\" {\ns = %s\n}\n", defaultExpr(t))
+		g.printIfNotOmitted(t, "strs = append(strs, s)")
+		g.printf("}\n")
+		g.printf("if err != nil {\n%s} else {\n", appendErr)
+		g.printf("labels[%s] = strings.Join(strs, %s)\n", key, splitExpr(t))
+		if f.legacy != "" {
+			g.printf("labels[%s] = labels[%s]\n", f.legacy, key)
+		}
+		g.printf("}\n")
+		g.addImport("strings", "strings")
+		return nil
+	}
+
+	ft := f.typ
+	if p, ok := ft.(*types.Pointer); ok {
+		ft = p.Elem()
+		n := g.newVar("p")
+		g.printf("%s := %s\n", n, x)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", n, n, g.typeString(ft))
+		x = "(*" + n + ")"
+	}
+	switch {
+	case g.implements(ft, "MarshalerWithOpts"):
+		g.printf("m, err := %s.MarshalLabels(o)\n", recv(x))
+		g.printf("if err != nil {\n%s}\n", appendErr)
+		g.printf("for key, value := range m {\nlabels[key] = value\n}\n")
+		return nil
+	case g.implements(ft, "Marshaler"):
+		g.printf("m, err := %s.MarshalLabels()\n", recv(x))
+		g.printf("if err != nil {\n%s}\n", appendErr)
+		g.printf("for key, value := range m {\nlabels[key] = value\n}\n")
+		return nil
+	}
+	g.printf("var s string\nvar err error\n")
+	if err := g.format(ft, x, "s", t); err != nil {
+		return fmt.Errorf(".%s: %w", f.name, err)
+	}
+	g.printf("if err != nil {\n%s} else {\n", appendErr)
+	g.printf("if s == \"\" {\ns = %s\n}\n", defaultExpr(t))
+	set := fmt.Sprintf("labels[%s] = s", key)
+	if f.legacy != "" {
+		set += fmt.Sprintf("\nlabels[%s] = s", f.legacy)
+	}
+	g.printIfNotOmitted(t, set)
+	g.printf("}\n")
+	return nil
+}
+
+// printIfNotOmitted prints stmt, guarded by a check of s if empty values are
+// to be omitted.
+func (g *generator) printIfNotOmitted(t *labeler.Tag, stmt string) {
+	switch omit := omitEmptyExpr(t); omit {
+	case "true":
+		g.printf("if s != \"\" {\n%s\n}\n", stmt)
+	case "false":
+		g.printf("%s\n", stmt)
+	default:
+		g.printf("if s != \"\" || !%s {\n%s\n}\n", omit, stmt)
+	}
+}
+
+// canFormat reports whether t can be formatted as an element of a collection.
+func (g *generator) canFormat(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if isStdlibType(t) {
+		return true
+	}
+	if g.implements(t, "Stringer") || g.implements(t, "TextMarshaler") {
+		return true
+	}
+	_, ok := basicKind(t)
+	return ok
+}
+
+// format prints the statements that format x, of type t, into the string
+// variable out. err is assigned if formatting fails.
+func (g *generator) format(t types.Type, x string, out string, tag *labeler.Tag) error {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+		n := g.newVar("p")
+		g.printf("%s := %s\n", n, x)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", n, n, g.typeString(t))
+		x = "(*" + n + ")"
+	}
+	switch {
+	case isPkgType(t, "time", "Time"):
+		g.printf("%s = %s.Format(%s)\n", out, x, timeFormatExpr(tag))
+		return nil
+	case isPkgType(t, "time", "Duration"):
+		g.printf("%s = %s.String()\n", out, x)
+		return nil
+	case isPkgType(t, "net", "IP"):
+		g.printf("if len(%s) > 0 {\n%s = %s.String()\n}\n", x, out, x)
+		return nil
+	case isPkgType(t, "net", "IPNet"):
+		g.printf("if %s.IP != nil {\n%s = %s.String()\n}\n", x, out, recv(x))
+		return nil
+	case isPkgType(t, "net/netip", "Addr"), isPkgType(t, "net/netip", "Prefix"):
+		g.printf("if %s.IsValid() {\n%s = %s.String()\n}\n", x, out, x)
+		return nil
+	case isPkgType(t, "math/big", "Int"):
+		g.printf("%s = %s.Text(%s)\n", out, recv(x), intBaseExpr(tag))
+		return nil
+	case isPkgType(t, "math/big", "Float"):
+		g.printf("%s = %s.Text(%s, -1)\n", out, recv(x), floatFormatExpr(tag))
+		return nil
+	case isPkgType(t, "net/url", "URL"), isPkgType(t, "regexp", "Regexp"):
+		g.printf("%s = %s.String()\n", out, recv(x))
+		return nil
+	case g.implements(t, "Stringer"):
+		g.printf("%s = %s.String()\n", out, recv(x))
+		return nil
+	case g.implements(t, "TextMarshaler"):
+		g.printf("if text, e := %s.MarshalText(); e != nil {\nerr = e\n} else {\n%s = string(text)\n}\n", recv(x), out)
+		return nil
+	}
+	kind, ok := basicKind(t)
+	if !ok {
+		return errUnsupportedType
+	}
+	g.addImport("strconv", "strconv")
+	switch kind {
+	case types.String:
+		g.printf("%s = string(%s)\n", out, x)
+	case types.Bool:
+		g.printf("%s = strconv.FormatBool(bool(%s))\n", out, x)
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		g.printf("%s = strconv.FormatInt(int64(%s), %s)\n", out, x, intBaseExpr(tag))
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		g.printf("%s = strconv.FormatUint(uint64(%s), %s)\n", out, x, uintBaseExpr(tag))
+	case types.Float32, types.Float64:
+		g.printf("%s = strconv.FormatFloat(float64(%s), %s, -1, %d)\n", out, x, floatFormatExpr(tag), bitSize(kind))
+	case types.Complex64, types.Complex128:
+		g.printf("%s = strconv.FormatComplex(complex128(%s), %s, -1, %d)\n", out, x, complexFormatExpr(tag), bitSize(kind))
+	}
+	return nil
+}
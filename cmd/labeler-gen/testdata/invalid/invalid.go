@@ -0,0 +1,17 @@
+package invalid
+
+type Unexported struct {
+	name string `label:"name"`
+}
+
+type BadContainer struct {
+	Labels map[string]int `label:"*"`
+}
+
+type Unsupported struct {
+	Ch chan int `label:"ch"`
+}
+
+type Constrained struct {
+	Port int `label:"port,min:1"`
+}
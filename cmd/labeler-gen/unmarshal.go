@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/chanced/labeler"
+)
+
+func (g *generator) generateUnmarshal(tm *typeModel) error {
+	g.printf("// UnmarshalLabels unmarshals labels into v.\n")
+	g.printf("func (v *%s) UnmarshalLabels(m map[string]string, o labeler.Options) error {\n", tm.name)
+	g.printf("labels := labeler.NewLabelSet(m)\n")
+	g.printf("errs := []*labeler.FieldError{}\n")
+	g.printContainerOptions(tm)
+	if err := g.unmarshalStruct(tm.root, "v"); err != nil {
+		return fmt.Errorf("%s%w", tm.name, err)
+	}
+	g.printf("if len(errs) > 0 {\nreturn labeler.NewParsingError(errs)\n}\n")
+	g.unmarshalContainer(tm)
+	g.printf("}\n\n")
+	return nil
+}
+
+func (g *generator) unmarshalContainer(tm *typeModel) {
+	switch {
+	case tm.container != nil:
+		// existing labels are kept unless the input has the same key
+		g.printf("if o.MergeMode == labeler.MergeCollections {\n")
+		g.printf("for k, s := range %s {\nif _, ok := labels.Get(k, false); !ok {\nlabels.Set(k, s)\n}\n}\n}\n", tm.container.expr)
+		g.printf("%s = labels.Map()\nreturn nil\n", tm.container.expr)
+	case g.implements(tm.typ, "GenericLabelee"):
+		g.printf("return v.SetLabels(labels.Map(), o.Tag)\n")
+	case g.implements(tm.typ, "StrictLabelee"):
+		g.printf("return v.SetLabels(labels.Map())\n")
+	case g.implements(tm.typ, "Labelee"):
+		g.printf("v.SetLabels(labels.Map())\nreturn nil\n")
+	default:
+		g.printf("return nil\n")
+	}
+}
+
+func (g *generator) unmarshalStruct(sm *structModel, recv string) error {
+	for _, f := range sm.fields {
+		x := recv + "." + f.name
+		if f.nested == nil {
+			if err := g.unmarshalField(f, x); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.isPtr {
+			if err := g.unmarshalStruct(f.nested, x); err != nil {
+				return err
+			}
+			continue
+		}
+		// the pointer is only assigned if one of the nested fields is set.
+		n := g.newVar("n")
+		set := g.newVar("set")
+		g.printf("{\n%s := %s\n", n, x)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", n, n, g.typeString(f.elem))
+		g.printf("%s := false\n", set)
+		g.sets = append(g.sets, set)
+		if err := g.unmarshalStruct(f.nested, n); err != nil {
+			return err
+		}
+		g.sets = g.sets[:len(g.sets)-1]
+		g.printf("if %s {\n%s = %s\n}\n}\n", set, x, n)
+	}
+	return nil
+}
+
+func (g *generator) unmarshalField(f *fieldModel, x string) error {
+	t := f.tag
+	key := f.key
+	g.printf("{\n")
+	defer g.printf("}\n")
+	g.printf("var err error\n")
+
+	ft := f.typ
+	target := x
+	if p, ok := ft.(*types.Pointer); ok {
+		ft = p.Elem()
+		target = "(*" + x + ")"
+	}
+
+	// fields which unmarshal themselves are passed all labels
+	var call string
+	switch {
+	case g.implements(ft, "UnmarshalerWithOpts"):
+		call = "UnmarshalLabels(labels.Map(), o)"
+	case g.implements(ft, "Unmarshaler"):
+		call = "UnmarshalLabels(labels.Map())"
+	}
+	if elem, ok := g.collection(ft); ok && g.canParse(elem) {
+		call = ""
+	}
+	// required fields which are missing are reported without being parsed.
+	missing := ""
+	switch req := requiredExpr(t); {
+	case req == "false" || t.DefaultIsSet:
+	case req == "true":
+		missing = "!ok"
+	default:
+		missing = "!ok && " + req
+	}
+	if len(f.keys) > 1 {
+		g.printf("keys := []string{%s}\n", strings.Join(f.keys, ", "))
+	}
+	// fields whose keys are missing are reset if o.ResetMissing is set
+	reset := !t.DefaultIsSet
+	if call != "" {
+		if reset {
+			if len(f.keys) > 1 {
+				g.printf("_, _, found := labels.Lookup(keys, %s)\n", ignoreCaseExpr(t))
+			} else {
+				g.printf("_, found := labels.Get(%s, %s)\n", key, ignoreCaseExpr(t))
+			}
+		}
+		if missing != "" {
+			if len(f.keys) > 1 {
+				g.printf("if _, _, ok := labels.Lookup(keys, %s); %s {\n", ignoreCaseExpr(t), missing)
+			} else {
+				g.printf("if _, ok := labels.Get(%s, %s); %s {\n", key, ignoreCaseExpr(t), missing)
+			}
+			g.printf("err = labeler.ErrLabelRequired\n} else {\n")
+		}
+		if target != x {
+			g.printf("if %s == nil {\n%s = new(%s)\n}\n", x, x, g.typeString(ft))
+		}
+		g.printf("err = %s.%s\n", x, call)
+		if missing != "" {
+			g.printf("}\n")
+		}
+	} else {
+		if len(f.keys) > 1 {
+			g.printf("s, i, ok := labels.Lookup(keys, %s)\n", ignoreCaseExpr(t))
+			g.printf("key := keys[0]\nif i > 0 {\nkey = keys[i]\n}\n")
+			g.printf("if i > 0 && o.AliasHandler != nil {\n")
+			g.printf("o.AliasHandler(labeler.AliasMatch{Field: %q, Key: keys[0], Alias: keys[i]})\n}\n", f.path)
+		} else {
+			g.printf("s, ok := labels.Get(%s, %s)\n", key, ignoreCaseExpr(t))
+		}
+		if reset {
+			g.printf("found := ok\n")
+		}
+		if missing != "" {
+			g.printf("if %s {\nerr = labeler.ErrLabelRequired\n} else {\n", missing)
+		}
+		switch omit := omitEmptyExpr(t); {
+		case t.DefaultIsSet:
+			g.printf("if !ok {\ns, ok = %s, true\n}\n", strconv.Quote(t.Default))
+		case omit == "false":
+			g.printf("ok = true\n")
+		case omit != "true":
+			g.printf("if !ok && !%s {\nok = true\n}\n", omit)
+		}
+		g.printf("if ok {\n")
+		for _, set := range g.sets {
+			g.printf("%s = true\n", set)
+		}
+		if target != x {
+			g.printf("if %s == nil {\n%s = new(%s)\n}\n", x, x, g.typeString(ft))
+		}
+		if err := g.unmarshalValue(ft, target, t); err != nil {
+			return fmt.Errorf(".%s: %w", f.name, err)
+		}
+		g.printf("}\n")
+		if missing != "" {
+			g.printf("}\n")
+		}
+	}
+	errKey, errValue := key, ""
+	if call == "" {
+		errValue = "s"
+		if len(f.keys) > 1 {
+			errKey = "key"
+		}
+	}
+	g.printf("if err != nil {\nerrs = append(errs, %s)\n}", fieldErrorExpr(f, "labeler.OpUnmarshal", errKey, errValue))
+	if reset {
+		g.printf(" else if !found && o.ResetMissing {\nvar zero %s\n%s = zero\n}", g.typeString(f.typ), x)
+	}
+	g.printf("\n")
+	del := fmt.Sprintf("labels.Delete(%s)\n", key)
+	if len(f.keys) > 1 {
+		del = "for _, key := range keys {\nlabels.Delete(key)\n}\n"
+	}
+	switch keep := keepExpr(t); keep {
+	case "true":
+	case "false":
+		g.printf(del)
+	default:
+		g.printf("if !%s {\n%s}\n", keep, del)
+	}
+	return nil
+}
+
+// unmarshalValue prints the statements that unmarshal the string s into x,
+// which is of type t.
+func (g *generator) unmarshalValue(t types.Type, x string, tag *labeler.Tag) error {
+	elem, ok := g.collection(t)
+	if !ok || !g.canParse(elem) {
+		return g.parse(t, x, "s", tag)
+	}
+	g.addImport("strings", "strings")
+	replace := replaceExpr(tag)
+	if _, isArray := t.Underlying().(*types.Array); isArray {
+		if replace != "false" {
+			g.printf("if %s {\n%s = %s{}\n}\n", replace, x, g.typeString(t))
+		}
+		g.printf("for i, part := range strings.Split(s, %s) {\n", splitExpr(tag))
+		g.printf("if i >= len(%s) {\nbreak\n}\n", x)
+		if err := g.parse(elem, x+"[i]", "part", tag); err != nil {
+			return err
+		}
+		g.printf("if err != nil {\nbreak\n}\n}\n")
+		return nil
+	}
+	if replace != "false" {
+		g.printf("if %s {\n%s = nil\n}\n", replace, x)
+	}
+	g.printf("for _, part := range strings.Split(s, %s) {\n", splitExpr(tag))
+	g.printf("var elem %s\n", g.typeString(elem))
+	if err := g.parse(elem, "elem", "part", tag); err != nil {
+		return err
+	}
+	g.printf("if err != nil {\nbreak\n}\n")
+	g.printf("%s = append(%s, elem)\n}\n", x, x)
+	return nil
+}
+
+// canParse reports whether t can be parsed as an element of a collection.
+func (g *generator) canParse(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if isStdlibType(t) {
+		return true
+	}
+	if g.implements(t, "Stringee") || g.implements(t, "TextUnmarshaler") {
+		return true
+	}
+	_, ok := basicKind(t)
+	return ok
+}
+
+// parse prints the statements that parse the string variable src into x,
+// which is of type t. err is assigned if parsing fails.
+func (g *generator) parse(t types.Type, x string, src string, tag *labeler.Tag) error {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", x, x, g.typeString(t))
+		x = "(*" + x + ")"
+	}
+	switch {
+	case isPkgType(t, "time", "Time"):
+		g.addImport("time", "time")
+		g.printf("if tv, e := time.Parse(%s, %s); e != nil {\nerr = e\n} else {\n%s = tv\n}\n", timeFormatExpr(tag), src, x)
+		return nil
+	case isPkgType(t, "time", "Duration"):
+		g.addImport("time", "time")
+		g.printf("if d, e := time.ParseDuration(%s); e != nil {\nerr = e\n} else {\n%s = d\n}\n", src, x)
+		return nil
+	case isPkgType(t, "net", "IP"):
+		g.addImport("net", "net")
+		g.printf("if ip := net.ParseIP(%s); ip == nil {\nerr = &net.ParseError{Type: \"IP address\", Text: %s}\n} else {\n%s = ip\n}\n", src, src, x)
+		return nil
+	case isPkgType(t, "net", "IPNet"):
+		g.addImport("net", "net")
+		g.printf("if _, n, e := net.ParseCIDR(%s); e != nil {\nerr = e\n} else {\n%s = *n\n}\n", src, x)
+		return nil
+	case isPkgType(t, "net/url", "URL"):
+		g.addImport("net/url", "url")
+		g.printf("if u, e := url.Parse(%s); e != nil {\nerr = e\n} else {\n%s = *u\n}\n", src, x)
+		return nil
+	case isPkgType(t, "regexp", "Regexp"):
+		g.addImport("regexp", "regexp")
+		g.printf("if re, e := regexp.Compile(%s); e != nil {\nerr = e\n} else {\n%s = *re\n}\n", src, x)
+		return nil
+	case isPkgType(t, "math/big", "Int"):
+		g.addImport("strconv", "strconv")
+		g.printf("if _, ok := %s.SetString(%s, %s); !ok {\nerr = &strconv.NumError{Func: \"SetString\", Num: %s, Err: strconv.ErrSyntax}\n}\n", recv(x), src, intBaseExpr(tag), src)
+		return nil
+	case isPkgType(t, "math/big", "Float"):
+		g.printf("_, _, err = %s.Parse(%s, 0)\n", recv(x), src)
+		return nil
+	case isPkgType(t, "net/netip", "Addr"):
+		g.addImport("net/netip", "netip")
+		g.printf("if a, e := netip.ParseAddr(%s); e != nil {\nerr = e\n} else {\n%s = a\n}\n", src, x)
+		return nil
+	case isPkgType(t, "net/netip", "Prefix"):
+		g.addImport("net/netip", "netip")
+		g.printf("if p, e := netip.ParsePrefix(%s); e != nil {\nerr = e\n} else {\n%s = p\n}\n", src, x)
+		return nil
+	case g.implements(t, "Stringee"):
+		g.printf("err = %s.FromString(%s)\n", recv(x), src)
+		return nil
+	case g.implements(t, "TextUnmarshaler"):
+		g.printf("err = %s.UnmarshalText([]byte(%s))\n", recv(x), src)
+		return nil
+	}
+	kind, ok := basicKind(t)
+	if !ok {
+		return errUnsupportedType
+	}
+	typ := g.typeString(t)
+	if kind == types.String {
+		g.printf("%s = %s(%s)\n", x, typ, src)
+		return nil
+	}
+	g.addImport("strconv", "strconv")
+	var parse string
+	switch kind {
+	case types.Bool:
+		parse = fmt.Sprintf("strconv.ParseBool(%s)", src)
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		parse = fmt.Sprintf("strconv.ParseInt(%s, %s, %d)", src, intBaseExpr(tag), bitSize(kind))
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		parse = fmt.Sprintf("strconv.ParseUint(%s, %s, %d)", src, uintBaseExpr(tag), bitSize(kind))
+	case types.Float32, types.Float64:
+		parse = fmt.Sprintf("strconv.ParseFloat(%s, %d)", src, bitSize(kind))
+	case types.Complex64, types.Complex128:
+		parse = fmt.Sprintf("strconv.ParseComplex(%s, %d)", src, bitSize(kind))
+	}
+	g.printf("if n, e := %s; e != nil {\nerr = e\n} else {\n%s = %s(n)\n}\n", parse, x, typ)
+	return nil
+}
@@ -0,0 +1,80 @@
+package labeler
+
+import "reflect"
+
+// EncodeFunc formats v, which is of the type it was registered for with
+// OptConverter, into a label value.
+type EncodeFunc func(v reflect.Value, tag Tag, o Options) (string, error)
+
+// DecodeFunc parses s into v, which is settable and of the type it was
+// registered for with OptConverter.
+type DecodeFunc func(s string, v reflect.Value, tag Tag, o Options) error
+
+type converter struct {
+	encode EncodeFunc
+	decode DecodeFunc
+}
+
+type converters map[reflect.Type]converter
+
+// with returns a copy of c with conv registered for t so that Options sharing
+// c are unaffected.
+func (c converters) with(t reflect.Type, conv converter) converters {
+	res := make(converters, len(c)+1)
+	for k, v := range c {
+		res[k] = v
+	}
+	res[t] = conv
+	return res
+}
+
+// addressed adapts conv, registered for a pointer type, to the type it points
+// to. Fields are dereferenced before converters are looked up, so values are
+// addressed before they are passed to conv.
+func (conv converter) addressed() converter {
+	res := converter{}
+	if conv.encode != nil {
+		res.encode = func(v reflect.Value, tag Tag, o Options) (string, error) {
+			if !v.CanAddr() {
+				pv := reflect.New(v.Type())
+				pv.Elem().Set(v)
+				return conv.encode(pv, tag, o)
+			}
+			return conv.encode(v.Addr(), tag, o)
+		}
+	}
+	if conv.decode != nil {
+		res.decode = func(s string, v reflect.Value, tag Tag, o Options) error {
+			return conv.decode(s, v.Addr(), tag, o)
+		}
+	}
+	return res
+}
+
+var marshalConverter = func(r reflected, o Options) marshalFunc {
+	if r.Topic() != fieldTopic {
+		return nil
+	}
+	conv, ok := o.converters[r.Type()]
+	if !ok || conv.encode == nil {
+		return nil
+	}
+	var fstr fieldStringer = func(f *field, o Options) (string, error) {
+		return conv.encode(f.value, f.tagValue(), o)
+	}
+	return fstr.Marshaler(r, o)
+}
+
+var unmarshalConverter = func(r reflected, o Options) unmarshalFunc {
+	if r.Topic() != fieldTopic || !r.CanSet() {
+		return nil
+	}
+	conv, ok := o.converters[r.Type()]
+	if !ok || conv.decode == nil {
+		return nil
+	}
+	var fstr fieldStrUnmarshalFunc = func(f *field, s string, o Options) error {
+		return conv.decode(s, f.value, f.tagValue(), o)
+	}
+	return fstr.Unmarshaler(r, o)
+}
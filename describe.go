@@ -0,0 +1,253 @@
+package labeler
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema describes the labels consumed and produced by a type.
+type Schema struct {
+	// Type is the Go type described
+	Type string
+	// Keys describes the key of each tagged field, in the order they are
+	// declared. Keys of the elements of slices, arrays and prefixed maps of
+	// structs are included with "*" in place of the index or map key, such as
+	// "servers.*.host".
+	Keys []KeySchema
+	// Leftover describes what receives the labels which are not assigned to a
+	// field.
+	Leftover Leftover
+}
+
+// Key returns the KeySchema for key, if present.
+func (s Schema) Key(key string) (KeySchema, bool) {
+	for _, k := range s.Keys {
+		if k.Key == key {
+			return k, true
+		}
+	}
+	return KeySchema{}, false
+}
+
+// KeySchema describes a label key along with how the field it belongs to is
+// marshaled and unmarshaled, with Options applied.
+type KeySchema struct {
+	// Key is the label key. Keys of prefixed maps end in ContainerToken.
+	Key string
+	// Aliases are the keys, set with AliasToken, which are also accepted
+	Aliases []string
+	// Legacy is the key, set with LegacyToken, which is also written
+	Legacy string
+	// Path is the dotted path of the field, such as "Database.Port"
+	Path string
+	// Type is the Go type of the field, as formatted by reflect.Type's String
+	Type string
+	// Default is the value used when the key is absent, if HasDefault
+	Default    string
+	HasDefault bool
+	Required   bool
+	// Split joins the elements of slices and arrays. It is empty for other
+	// types.
+	Split string
+	// Format is the layout of time.Time or the format of floats and complex
+	// numbers. It is empty for other types.
+	Format string
+	// Base is the base of integers. It is 0 for other types.
+	Base       int
+	Keep       bool
+	IgnoreCase bool
+	OmitEmpty  bool
+	// Doc is the description set with DocToken
+	Doc string
+}
+
+// Leftover describes the recipient of labels which are not assigned to a
+// field.
+type Leftover struct {
+	// Path is the path of the container field. It is empty if the labels are
+	// handled by the type itself or not at all.
+	Path string
+	// Type is the Go type of the container field or, if Path is empty, the type
+	// described.
+	Type string
+	// Interface is the interface, such as "Labelee" or "GenericLabelee",
+	// through which the labels are set. It is empty if the container is a map
+	// which is assigned directly or if there is no recipient. If Interface is
+	// "Unmarshaler" or "UnmarshalerWithOpts", every label is handed to the type.
+	Interface string
+}
+
+// Describe returns the Schema of v using the Options provided. See
+// Labeler.Describe.
+func Describe(v interface{}, opts ...Option) (Schema, error) {
+	lbl := newLabeler(opts)
+	return lbl.Describe(v)
+}
+
+// Describe returns the Schema of v, which may be a nil pointer, using the
+// Options provided to Labeler. The fields of v are not read.
+func (lbl *Labeler) Describe(v interface{}) (Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return Schema{}, ErrInvalidValue
+	}
+	if t.Kind() != reflect.Ptr {
+		t = reflect.PtrTo(t)
+	}
+	o := lbl.options
+	sub, err := walkSubject(reflect.New(t.Elem()), o)
+	if err != nil {
+		return Schema{}, err
+	}
+	s := Schema{Type: t.Elem().String()}
+	o = o.FromTag(sub.containerTag())
+	s.Keys, err = describeFields(sub, "", "", o)
+	if err != nil {
+		return s, err
+	}
+	switch {
+	case sub.unmarshal != nil:
+		s.Leftover = Leftover{Type: s.Type, Interface: leftoverInterface(t.Elem())}
+	case sub.container != nil:
+		ct := sub.container.parent.Type().Field(sub.container.index).Type
+		s.Leftover = Leftover{Path: sub.container.path, Type: ct.String(), Interface: leftoverInterface(ct)}
+	}
+	return s, nil
+}
+
+// walkSubject returns the subject of rv, a pointer to a struct. Unlike
+// newSubject, the fields of generated types are walked.
+func walkSubject(rv reflect.Value, o Options) (subject, error) {
+	if o.customTokenErr != nil {
+		return subject{}, o.customTokenErr
+	}
+	sub := subject{
+		meta:     newMeta(rv),
+		fieldset: newFieldset(),
+	}
+	if !sub.IsStruct() {
+		return sub, ErrInvalidValue
+	}
+	sub.unmarshal = getUnmarshal(&sub, o)
+	err := sub.init(o)
+	return sub, err
+}
+
+func describeFields(sub subject, path string, keyPrefix string, o Options) ([]KeySchema, error) {
+	keys := []KeySchema{}
+	for _, f := range sub.tagged {
+		sf, _ := f.parent.StructField(f.index)
+		ks := KeySchema{
+			Key:        keyPrefix + f.key,
+			Path:       path + f.path,
+			Type:       sf.Type.String(),
+			HasDefault: f.HasDefault(o),
+			Required:   f.Required(o),
+			Keep:       f.ShouldKeep(o),
+			IgnoreCase: f.ignoreCase(o),
+			OmitEmpty:  f.OmitEmpty(o),
+			Doc:        f.tag.Doc,
+		}
+		if ks.HasDefault {
+			ks.Default = f.Default(o)
+		}
+		for _, alias := range f.keys[1:] {
+			ks.Aliases = append(ks.Aliases, keyPrefix+alias)
+		}
+		if f.legacyKey != "" {
+			ks.Legacy = keyPrefix + f.legacyKey
+		}
+		et := sf.Type
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		if (et.Kind() == reflect.Slice || et.Kind() == reflect.Array) && !isPkgType(et) && !f.isIndexed {
+			ks.Split = f.split(o)
+		}
+		if et.Kind() == reflect.Slice || et.Kind() == reflect.Array || et.Kind() == reflect.Map {
+			if !isPkgType(et) {
+				et = et.Elem()
+			}
+		}
+		if et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		ks.Format, ks.Base = describeFormat(f, et, o)
+		keys = append(keys, ks)
+
+		var nested []KeySchema
+		var err error
+		switch {
+		case f.isIndexed:
+			before, after, ierr := indexAffixes(o)
+			if ierr != nil {
+				return keys, ierr
+			}
+			nested, err = describeElem(et, ks.Path+"[*].", ks.Key+before+"*"+after, o)
+		case f.mapPrefix != "" && et.Kind() == reflect.Struct:
+			nested, err = describeElem(et, ks.Path+"[*].", keyPrefix+f.mapPrefix+"*"+o.PathDelimiter, o)
+		}
+		if err != nil {
+			return keys, err
+		}
+		keys = append(keys, nested...)
+	}
+	return keys, nil
+}
+
+// describeElem describes the fields of t, the struct element of a collection.
+func describeElem(t reflect.Type, path string, keyPrefix string, o Options) ([]KeySchema, error) {
+	sub, err := walkSubject(reflect.New(t), o)
+	if err != nil {
+		return nil, err
+	}
+	return describeFields(sub, path, keyPrefix, o)
+}
+
+// describeFormat returns the format and base that apply to f, whose type (or
+// element type) is t.
+func describeFormat(f *field, t reflect.Type, o Options) (string, int) {
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return f.timeFormat(o), 0
+	case t == reflect.TypeOf(big.Int{}):
+		return "", f.intBase(o)
+	case t == reflect.TypeOf(big.Float{}):
+		return string(f.floatFormat(o)), 0
+	case t.Implements(stringeeType) || reflect.PtrTo(t).Implements(stringeeType):
+		return "", 0
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return string(f.floatFormat(o)), 0
+	case reflect.Complex64, reflect.Complex128:
+		return string(f.complexFormat(o)), 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == reflect.TypeOf(time.Duration(0)) {
+			return "", 0
+		}
+		return "", f.intBase(o)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "", f.uintBase(o)
+	}
+	return "", 0
+}
+
+// leftoverInterface returns the name of the interface through which t
+// receives labels, if any.
+func leftoverInterface(t reflect.Type) string {
+	for _, iface := range []reflect.Type{
+		unmarshalerWithOptsType,
+		unmarshalerType,
+		genericLabeleeType,
+		strictLabeleeType,
+		labeleeType,
+	} {
+		if t.Implements(iface) || (t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(iface)) {
+			return strings.TrimPrefix(iface.String(), "labeler.")
+		}
+	}
+	return ""
+}
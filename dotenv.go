@@ -0,0 +1,226 @@
+package labeler
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// UnmarshalDotenv parses the .env file read from r and unmarshals its
+// variables into v. Blank lines, comments starting with "#" and "export"
+// prefixes are ignored. Values can be single quoted, which are taken
+// literally, or double quoted, which may contain the escapes \n, \r, \t, \",
+// \\ and \$. Quoted values can span multiple lines. ${VAR}, $VAR and
+// ${VAR:-default} are expanded in unquoted and double quoted values using the
+// variables defined earlier in the file, followed by the environment (see
+// Options.Environ).
+//
+// As with UnmarshalEnv, v does not need a container and Options.EnvPrefix, if
+// set, limits the variables which are unmarshaled.
+func UnmarshalDotenv(r io.Reader, v interface{}, opts ...Option) error {
+	lbl := newLabeler(opts)
+	return lbl.UnmarshalDotenv(r, v)
+}
+
+// MarshalDotenv writes v to w as a .env file. The keys of each field are
+// written in the order the fields are declared, preceded by the field's
+// DocToken as a comment, followed by the remaining labels sorted by key.
+// Values are double quoted if necessary. Options.EnvPrefix, if set, is
+// prepended to each key.
+func MarshalDotenv(w io.Writer, v interface{}, opts ...Option) error {
+	lbl := newLabeler(opts)
+	return lbl.MarshalDotenv(w, v)
+}
+
+// UnmarshalDotenv parses the .env file read from r into v using the Options
+// provided to Labeler. See UnmarshalDotenv.
+func (lbl *Labeler) UnmarshalDotenv(r io.Reader, v interface{}) error {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	o := lbl.options
+	o.containerOptional = true
+	p := dotenvParser{vars: map[string]string{}, o: o}
+	if err := p.parse(string(src)); err != nil {
+		return err
+	}
+	return lbl.unmarshal(trimEnvPrefix(p.vars, o.EnvPrefix), v, o)
+}
+
+// MarshalDotenv writes v to w as a .env file using the Options provided to
+// Labeler. See MarshalDotenv.
+func (lbl *Labeler) MarshalDotenv(w io.Writer, v interface{}) error {
+	entries, o, err := lbl.marshalEntries(v)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		if e.Doc != "" {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("# " + e.Doc + "\n")
+		}
+		b.WriteString(o.EnvPrefix + e.Key + "=" + quoteDotenv(e.Value) + "\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// quoteDotenv double quotes s if it contains whitespace, quotes or characters
+// which would otherwise be interpreted, such as "#" and "$".
+func quoteDotenv(s string) string {
+	if !strings.ContainsAny(s, " \t\r\n\"'\\$#`") {
+		return s
+	}
+	return `"` + dotenvEscaper.Replace(s) + `"`
+}
+
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "$", `\$`)
+
+type dotenvParser struct {
+	vars map[string]string
+	// environ is parsed from Options.Environ when first needed for expansion
+	environ map[string]string
+	o       Options
+}
+
+func (p *dotenvParser) parse(src string) error {
+	lines := strings.Split(strings.Replace(src, "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		n := i + 1
+		line := strings.TrimLeft(lines[i], " \t")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimLeft(line[len("export"):], " \t")
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return &SyntaxError{Format: "dotenv", Line: n, Err: ErrSyntax}
+		}
+		key := strings.TrimRight(line[:eq], " \t")
+		if key == "" || strings.ContainsAny(key, " \t\"'") {
+			return &SyntaxError{Format: "dotenv", Line: n, Err: ErrSyntax}
+		}
+		rest := strings.TrimLeft(line[eq+1:], " \t")
+
+		var value string
+		var err error
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			q := rest[0]
+			body := rest[1:]
+			end := closingQuote(body, q)
+			for end < 0 {
+				i++
+				if i >= len(lines) {
+					return &SyntaxError{Format: "dotenv", Line: n, Err: ErrUnterminatedQuote}
+				}
+				body += "\n" + lines[i]
+				end = closingQuote(body, q)
+			}
+			if trailing := strings.TrimSpace(body[end+1:]); trailing != "" && trailing[0] != '#' {
+				return &SyntaxError{Format: "dotenv", Line: n, Err: ErrSyntax}
+			}
+			value = body[:end]
+			if q == '"' {
+				value, err = p.expand(value, true)
+			}
+		} else {
+			if j := strings.Index(rest, " #"); j >= 0 {
+				rest = rest[:j]
+			}
+			if j := strings.Index(rest, "\t#"); j >= 0 {
+				rest = rest[:j]
+			}
+			value, err = p.expand(strings.TrimRight(rest, " \t"), false)
+		}
+		if err != nil {
+			return &SyntaxError{Format: "dotenv", Line: n, Err: err}
+		}
+		p.vars[key] = value
+	}
+	return nil
+}
+
+// closingQuote returns the index of the quote q which ends s, skipping quotes
+// escaped with a backslash in double quoted values, or -1.
+func closingQuote(s string, q byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && q == '"':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// expand replaces the variables referenced by s with their values and, if
+// escapes is true, the escape sequences with the characters they represent.
+func (p *dotenvParser) expand(s string, escapes bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escapes && c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", ErrSyntax
+			}
+			expr := s[i+2 : i+2+end]
+			name, def := expr, ""
+			if j := strings.Index(expr, ":-"); j >= 0 {
+				name, def = expr[:j], expr[j+2:]
+			}
+			value := p.lookup(name)
+			if value == "" {
+				value = def
+			}
+			b.WriteString(value)
+			i += 2 + end
+		case c == '$' && i+1 < len(s) && isDotenvNameByte(s[i+1], true):
+			j := i + 1
+			for j < len(s) && isDotenvNameByte(s[j], false) {
+				j++
+			}
+			b.WriteString(p.lookup(s[i+1 : j]))
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// lookup returns the value of the variable name, defined either earlier in
+// the file or in the environment.
+func (p *dotenvParser) lookup(name string) string {
+	if value, ok := p.vars[name]; ok {
+		return value
+	}
+	if p.environ == nil {
+		p.environ = parseEnviron(p.o)
+	}
+	return p.environ[name]
+}
+
+func isDotenvNameByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
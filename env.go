@@ -0,0 +1,96 @@
+package labeler
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// UnmarshalEnv unmarshals the environment variables into v. The environment is
+// read from os.Environ() unless Options.Environ is set. v does not need a
+// container; variables which are not assigned to a field are ignored if it
+// lacks one. If Options.EnvPrefix is
+// set, only the variables starting with it are read and the prefix is removed
+// from their keys.
+func UnmarshalEnv(v interface{}, opts ...Option) error {
+	lbl := newLabeler(opts)
+	return lbl.UnmarshalEnv(v)
+}
+
+// MarshalEnv marshals v into environment variables of the form "KEY=value",
+// sorted by key and suitable for exec.Cmd's Env. Options.EnvPrefix, if set, is
+// prepended to each key.
+func MarshalEnv(v interface{}, opts ...Option) ([]string, error) {
+	lbl := newLabeler(opts)
+	return lbl.MarshalEnv(v)
+}
+
+// UnmarshalEnv unmarshals the environment variables into v using the Options
+// provided to Labeler. See UnmarshalEnv.
+func (lbl *Labeler) UnmarshalEnv(v interface{}) error {
+	o := lbl.options
+	o.containerOptional = true
+	return lbl.unmarshal(environMap(o), v, o)
+}
+
+// MarshalEnv marshals v into environment variables using the Options provided
+// to Labeler. See MarshalEnv.
+func (lbl *Labeler) MarshalEnv(v interface{}) ([]string, error) {
+	o := lbl.options
+	o.containerOptional = true
+	kvs, err := lbl.marshal(v, o)
+	if err != nil {
+		return nil, err
+	}
+	m := kvs.Map()
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, len(keys))
+	for i, k := range keys {
+		env[i] = o.EnvPrefix + k + "=" + m[k]
+	}
+	return env, nil
+}
+
+// environMap returns the variables of the environment which start with
+// EnvPrefix, with the prefix removed.
+func environMap(o Options) map[string]string {
+	return trimEnvPrefix(parseEnviron(o), o.EnvPrefix)
+}
+
+// parseEnviron parses the "KEY=value" pairs of Options.Environ, or
+// os.Environ() if it is nil. Entries without a key, such as the "=C:=C:\"
+// variables found on Windows, are skipped.
+func parseEnviron(o Options) map[string]string {
+	environ := o.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+	m := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			continue
+		}
+		m[kv[:i]] = kv[i+1:]
+	}
+	return m
+}
+
+// trimEnvPrefix returns the entries of m whose keys start with prefix, with
+// the prefix removed.
+func trimEnvPrefix(m map[string]string, prefix string) map[string]string {
+	if prefix == "" {
+		return m
+	}
+	res := make(map[string]string, len(m))
+	for key, value := range m {
+		if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			res[key[len(prefix):]] = value
+		}
+	}
+	return res
+}
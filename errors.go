@@ -1,6 +1,7 @@
 package labeler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -51,21 +52,111 @@ var (
 
 	// ErrSplitEmpty is returned when the split string is empty
 	ErrSplitEmpty = errors.New("split can not be empty")
-	// // ErrLabelRequired occurs  when a label is marked as required but not available.
-	// ErrLabelRequired = errors.New("value for this field is required")
 
+	// ErrInvalidPrefix is returned when a field marked with the prefix token is not a struct
+	ErrInvalidPrefix = errors.New("prefix is only valid on nested struct fields")
+
+	// ErrConstraint is wrapped by ValidationError when a field's value does not
+	// satisfy a constraint set on its tag
+	ErrConstraint = errors.New("value does not satisfy constraint")
+
+	// ErrInvalidIndex is wrapped by IndexError when the index of a label for a slice or
+	// array of structs is not a non-negative integer without leading zeros
+	ErrInvalidIndex = errors.New("invalid index")
+
+	// ErrIndexGap is wrapped by IndexError when the indices of labels for a slice or
+	// array of structs are not sequential, starting at 0
+	ErrIndexGap = errors.New("indices must be sequential, starting at 0; missing index")
+
+	// ErrIndexOutOfRange is wrapped by IndexError when the index of a label exceeds
+	// the length of an array of structs
+	ErrIndexOutOfRange = errors.New("index out of range")
+
+	// ErrUnknownKey is wrapped by UnknownKeysError when the input has keys which were
+	// not consumed by a field and Options.DisallowUnknownKeys is set
+	ErrUnknownKey = errors.New("unknown key")
+
+	// ErrKubernetes is wrapped by KubernetesError when a key or value does not satisfy
+	// the rules of Options.KubernetesMode
+	ErrKubernetes = errors.New("invalid for Kubernetes")
+
+	// ErrSyntax is wrapped by SyntaxError when a line of a .env, .properties or INI
+	// file is malformed
+	ErrSyntax = errors.New("invalid syntax")
+
+	// ErrUnterminatedQuote is wrapped by SyntaxError when a quoted value is not
+	// closed
+	ErrUnterminatedQuote = errors.New("unterminated quoted value")
+
+	// ErrLabelRequired occurs when a label is marked as required but not available.
+	ErrLabelRequired = errors.New("value for this field is required")
+)
+
+// Op is the operation during which a FieldError occurred.
+type Op string
+
+// Operations
+const (
+	// OpParse is the operation of parsing a field's tag
+	OpParse Op = "parse"
+	// OpUnmarshal is the operation of unmarshaling labels into a field
+	OpUnmarshal Op = "unmarshal"
+	// OpMarshal is the operation of marshaling a field into labels
+	OpMarshal Op = "marshal"
 )
 
 // FieldError is returned when there is an error parsing a field's tag due to
-// it being malformed or inaccessible.
+// it being malformed or inaccessible, or when a field can not be marshaled or
+// unmarshaled.
 type FieldError struct {
+	// Field is the name of the field
 	Field string
-	Tag   Tag
-	Err   error
+	// Path is the dotted path of the field from the value passed to Marshal or
+	// Unmarshal, such as "Database.Port" or "Servers[0].Port"
+	Path string
+	// Key is the label key of the field, or the alias that matched
+	Key string
+	// Value is the raw label value, if any
+	Value string
+	// Type is the Go type of the field, as formatted by reflect.Type's String
+	Type string
+	// Op is the operation that failed
+	Op  Op
+	Tag Tag
+	Err error
 }
 
 func (err *FieldError) Error() string {
-	return fmt.Sprintf("error parsing %s: %v", err.Field, err.Err)
+	name := err.Path
+	if name == "" {
+		name = err.Field
+	}
+	verb := "parsing"
+	switch err.Op {
+	case OpUnmarshal:
+		verb = "unmarshaling"
+	case OpMarshal:
+		verb = "marshaling"
+	}
+	if err.Key != "" {
+		return fmt.Sprintf("error %s %s (%q): %v", verb, name, err.Key, err.Err)
+	}
+	return fmt.Sprintf("error %s %s: %v", verb, name, err.Err)
+}
+
+// MarshalJSON encodes err as a JSON object with the fields "field", "path",
+// "key", "value", "type", "op" and "error", the message of Err. Empty fields
+// are omitted.
+func (err *FieldError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field string `json:"field,omitempty"`
+		Path  string `json:"path,omitempty"`
+		Key   string `json:"key,omitempty"`
+		Value string `json:"value,omitempty"`
+		Type  string `json:"type,omitempty"`
+		Op    Op     `json:"op,omitempty"`
+		Error string `json:"error,omitempty"`
+	}{err.Field, err.Path, err.Key, err.Value, err.Type, err.Op, errorString(err.Err)})
 }
 
 func (err *FieldError) Unwrap() error {
@@ -94,7 +185,47 @@ func NewFieldErrorWithTag(field string, t *Tag, err error) *FieldError {
 }
 
 func newFieldError(f *field, err error) *FieldError {
-	return NewFieldErrorWithTag(f.name, f.tag, err)
+	if fe, ok := err.(*FieldError); ok && fe.Path == f.path {
+		// already describes f
+		return fe
+	}
+	fe := NewFieldErrorWithTag(f.name, f.tag, err)
+	fe.Path = f.path
+	fe.Key = f.matchedKey
+	if fe.Key == "" {
+		fe.Key = f.key
+	}
+	fe.Value = f.raw
+	fe.Op = f.op
+	if fe.Op == "" {
+		fe.Op = OpParse
+	}
+	if sf, ok := f.parent.StructField(f.index); ok {
+		fe.Type = sf.Type.String()
+	}
+	return fe
+}
+
+// nestFieldErrors prefixes the Path and Key of the FieldErrors of err, a
+// ParsingError from the struct element of a collection, with path and
+// keyPrefix respectively. Other errors are returned as is.
+func nestFieldErrors(err error, path string, keyPrefix string) error {
+	pErr, ok := err.(*ParsingError)
+	if !ok {
+		return err
+	}
+	for _, fe := range pErr.Errors {
+		fe.Path = path + "." + fe.Path
+		fe.Key = keyPrefix + fe.Key
+	}
+	return pErr
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
 }
 
 // ParsingError is returned when there are 1 or more errors parsing a value. Check Errors for individual FieldErrors.
@@ -118,6 +249,16 @@ func (err *ParsingError) getFieldErrors() (int, string) {
 	return count, strings.Join(fields, ", ")
 }
 
+// MarshalJSON encodes err as a JSON object with the fields "error", the
+// summary of err, and "errors", the FieldErrors.
+func (err *ParsingError) MarshalJSON() ([]byte, error) {
+	count, fields := err.getFieldErrors()
+	return json.Marshal(struct {
+		Error  string        `json:"error"`
+		Errors []*FieldError `json:"errors"`
+	}{fmt.Sprintf("%d %v (%s)", count, ErrParsing, fields), err.Errors})
+}
+
 func (err *ParsingError) Unwrap() error {
 	count, fields := err.getFieldErrors()
 	return fmt.Errorf("%d %w (%s)", count, ErrParsing, fields)
@@ -153,3 +294,36 @@ func (err *OptionError) Unwrap() error {
 	return fmt.Errorf("%w %s: %v", ErrInvalidOption, err.Option, err.Msg)
 
 }
+
+// UnknownKey is a key of the input which was not consumed by a field.
+type UnknownKey struct {
+	// Key is the unknown key as it appears in the input
+	Key string
+	// Suggestion is the known key closest to Key, if any are reasonably close
+	Suggestion string
+}
+
+func (k UnknownKey) String() string {
+	if k.Suggestion == "" {
+		return fmt.Sprintf("%q", k.Key)
+	}
+	return fmt.Sprintf("%q (did you mean %q?)", k.Key, k.Suggestion)
+}
+
+// UnknownKeysError is returned from Unmarshal when Options.DisallowUnknownKeys
+// is set and the input has keys which were not consumed by a field.
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+func (err *UnknownKeysError) Error() string {
+	keys := make([]string, len(err.Keys))
+	for i, k := range err.Keys {
+		keys[i] = k.String()
+	}
+	return fmt.Sprintf("%v(s): %s", ErrUnknownKey, strings.Join(keys, ", "))
+}
+
+func (err *UnknownKeysError) Unwrap() error {
+	return ErrUnknownKey
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,13 +13,26 @@ type field struct {
 	meta
 	tag         *Tag
 	parent      reflected
+	index       int
 	name        string
 	path        string
 	key         string
+	keys        []string
+	legacyKey   string
+	keyPrefix   string
+	mapPrefix   string
+	namespace   string
+	isIndexed   bool
 	wasSet      bool
 	Keep        bool
 	isTagged    bool
 	isContainer bool
+	isPrefix    bool
+	isIgnored   bool
+	// op, matchedKey and raw describe the current operation for FieldErrors
+	op         Op
+	matchedKey string
+	raw        string
 }
 
 func newField(parent reflected, i int, o Options) (*field, error) {
@@ -35,17 +49,21 @@ func newField(parent reflected, i int, o Options) (*field, error) {
 	f := &field{
 		name:   fieldName,
 		parent: parent,
+		index:  i,
 	}
 	f.path = f.Path()
+	if p, ok := parent.(*field); ok {
+		f.keyPrefix = p.nestedKeyPrefix(o)
+	}
 	tag, err := f.parseTag(sf, o)
 
 	if err != nil {
 		return f, f.err(err)
 	}
-	f.tag = tag
 	if tag != nil {
-		f.key = tag.Key
+		f.namespace = tag.KeyPrefix
 	}
+	f.setTag(tag)
 
 	f.meta = newMeta(rv)
 
@@ -57,12 +75,22 @@ func newField(parent reflected, i int, o Options) (*field, error) {
 		return f, f.err(ErrUnexportedField)
 	}
 
-	if f.isTagged || f.IsContainer(o) {
+	f.isContainer = f.IsContainer(o)
 
-		if f.kind == reflect.Map && f.value.IsNil() {
-			f.value = reflect.New(f.typ).Elem()
+	if tag != nil && tag.Prefix && !f.isNamespaced(o) {
+		if f.isContainer || !f.IsStruct() {
+			return f, f.err(ErrInvalidPrefix)
 		}
+		f.isPrefix = true
+		return f, nil
+	}
 
+	if !f.isTagged && !f.isContainer && !f.isIgnored && o.NamingStrategy != nil && sf.PkgPath == "" {
+		f.deriveTag(o)
+		return f, nil
+	}
+
+	if f.isTagged || f.isContainer {
 		f.unmarshal = getUnmarshal(f, o)
 		f.marshal = getMarshal(f, o)
 		if f.unmarshal == nil {
@@ -72,11 +100,56 @@ func newField(parent reflected, i int, o Options) (*field, error) {
 		if f.marshal == nil {
 			return f, f.err(ErrUnsupportedType)
 		}
+		if err := f.parseBounds(sf); err != nil {
+			return f, f.err(err)
+		}
 	}
 
 	return f, nil
 }
 
+func (f *field) setTag(tag *Tag) {
+	f.tag = tag
+	if tag == nil {
+		return
+	}
+	prefix := f.keyPrefix + f.namespace
+	f.key = prefix + tag.Key
+	f.keys = []string{f.key}
+	for _, alias := range tag.Aliases {
+		f.keys = append(f.keys, prefix+alias)
+	}
+	if tag.Legacy != "" {
+		f.legacyKey = prefix + tag.Legacy
+	}
+	if tag.MapPrefix != "" {
+		f.mapPrefix = prefix + tag.MapPrefix
+	}
+}
+
+// isNamespaced reports whether f is tagged with a value for PrefixToken, such
+// as `label:"team,prefix:example.com/"`, while being marshaled as a value
+// rather than as a nested struct. The value then namespaces f's keys.
+func (f *field) isNamespaced(o Options) bool {
+	return !f.isContainer && f.namespace != "" && getUnmarshal(f, o) != nil
+}
+
+// deriveTag assigns f a key from Options.NamingStrategy if f is of a type
+// that can be marshaled and unmarshaled. Otherwise f is left untagged so that
+// nested structs are still walked.
+func (f *field) deriveTag(o Options) {
+	f.setTag(&Tag{Key: o.NamingStrategy(f.name)})
+	f.unmarshal = getUnmarshal(f, o)
+	f.marshal = getMarshal(f, o)
+	if f.unmarshal == nil || f.marshal == nil {
+		f.setTag(nil)
+		f.unmarshal = nil
+		f.marshal = nil
+		return
+	}
+	f.isTagged = true
+}
+
 func (f *field) Unmarshal(kvs *keyValues, o Options) error {
 	if f.unmarshal == nil {
 		// this shouldn't happen. just being safe.
@@ -92,6 +165,33 @@ func (f *field) Marshal(kvs *keyValues, o Options) error {
 	return f.marshal(f, kvs, o)
 }
 
+// lookup returns the value of f's key, falling back to its aliases in order.
+// Matches on an alias are reported to Options.AliasHandler.
+func (f *field) lookup(kvs *keyValues, o Options) (keyvalue, bool) {
+	kv, i, ok := kvs.GetFirst(f.keys, f.ignoreCase(o))
+	if ok {
+		f.matchedKey, f.raw = kv.Key, kv.Value
+	}
+	if i > 0 && o.AliasHandler != nil {
+		o.AliasHandler(AliasMatch{Field: f.path, Key: f.key, Alias: f.keys[i]})
+	}
+	return kv, ok
+}
+
+// isPresent reports whether f's key or any of its aliases are in kvs.
+func (f *field) isPresent(kvs *keyValues, o Options) bool {
+	if f.mapPrefix != "" {
+		return len(f.prefixedKeys(kvs, o)) > 0
+	}
+	if f.isIndexed {
+		groups, _, err := f.indexedKeys(kvs, o)
+		// errors are reported when f is unmarshaled
+		return err != nil || len(groups) > 0
+	}
+	_, _, ok := kvs.GetFirst(f.keys, f.ignoreCase(o))
+	return ok
+}
+
 func (f *field) HasDefault(o Options) bool {
 	return f.tag != nil && f.tag.DefaultIsSet
 }
@@ -105,6 +205,10 @@ func (f *field) ignoreCase(o Options) bool {
 
 func (f *field) parseTag(sf reflect.StructField, o Options) (*Tag, error) {
 	tagstr, isTagged := sf.Tag.Lookup(o.Tag)
+	if strings.TrimSpace(tagstr) == "-" {
+		f.isIgnored = true
+		return nil, nil
+	}
 	f.isTagged = isTagged
 	if !isTagged {
 		return nil, nil
@@ -112,6 +216,15 @@ func (f *field) parseTag(sf reflect.StructField, o Options) (*Tag, error) {
 	return newTag(tagstr, o)
 }
 
+// nestedKeyPrefix returns the prefix for the keys of the fields nested within
+// f. Untagged structs pass along their own prefix.
+func (f *field) nestedKeyPrefix(o Options) string {
+	if !f.isPrefix {
+		return f.keyPrefix
+	}
+	return f.keyPrefix + f.namespace + f.tag.Key + o.PathDelimiter
+}
+
 func (f *field) IsContainer(o Options) bool {
 	switch {
 	case o.ContainerField != "" && o.ContainerField == f.path:
@@ -133,6 +246,18 @@ func (f *field) Path() string {
 	return f.name
 }
 
+// nestedErrors returns the FieldErrors of err if it is a ParsingError from
+// the struct elements of f, a slice, array or prefixed map of structs. Their
+// paths and keys are prefixed with those of the element (see
+// nestFieldErrors).
+func (f *field) nestedErrors(err error) ([]*FieldError, bool) {
+	pErr, ok := err.(*ParsingError)
+	if !ok || (!f.isIndexed && f.mapPrefix == "") {
+		return nil, false
+	}
+	return pErr.Errors, true
+}
+
 func (f *field) err(err error) *FieldError {
 	if err != nil {
 		return newFieldError(f, err)
@@ -140,6 +265,14 @@ func (f *field) err(err error) *FieldError {
 	return nil
 }
 
+// tagValue returns a copy of f's tag, or an empty Tag if f is not tagged.
+func (f *field) tagValue() Tag {
+	if f.tag == nil {
+		return Tag{}
+	}
+	return *f.tag
+}
+
 func (f *field) split(o Options) string {
 	if s, ok := f.tag.GetSplit(); ok {
 		return s
@@ -213,7 +346,7 @@ func (f *field) formatUint(o Options) (string, error) {
 	switch f.kind {
 	case reflect.Uint, reflect.Uint64, reflect.Uint32, reflect.Uint16, reflect.Uint8:
 		v := f.value.Uint()
-		return strconv.FormatUint(v, f.intBase(o)), nil
+		return strconv.FormatUint(v, f.uintBase(o)), nil
 	default:
 		return "", nil
 	}
@@ -333,6 +466,17 @@ func (f *field) setMap(v map[string]string, o Options) error {
 	if f.kind != reflect.Map {
 		return f.err(errors.New("invalid type")) // this shouldn't happen
 	}
+	if f.merges(o) && !f.value.IsNil() {
+		merged := make(map[string]string, f.value.Len()+len(v))
+		iter := f.value.MapRange()
+		for iter.Next() {
+			merged[iter.Key().String()] = iter.Value().String()
+		}
+		for k, val := range v {
+			merged[k] = val
+		}
+		v = merged
+	}
 	f.value.Set(reflect.ValueOf(v))
 	return nil
 }
@@ -356,6 +500,42 @@ func (f *field) OmitEmpty(o Options) bool {
 	return o.OmitEmpty
 }
 
+// Required reports whether the field's key must be present in the labels.
+func (f *field) Required(o Options) bool {
+	if f.tag.RequiredIsSet {
+		return f.tag.Required
+	}
+	return o.RequireAllFields
+}
+
+// merges reports whether f's existing value is merged with, rather than
+// replaced by, its labels. See MergeMode.
+func (f *field) merges(o Options) bool {
+	if f.tag != nil && f.tag.MergeIsSet {
+		return f.tag.Merge
+	}
+	switch o.MergeMode {
+	case MergeCollections:
+		return true
+	case ReplaceCollections:
+		return false
+	}
+	return !f.isContainer
+}
+
+// reset sets f to its zero value.
+func (f *field) reset() {
+	if f.isPtr {
+		if f.ptrValue.CanSet() {
+			f.ptrValue.Set(reflect.Zero(f.ptrType))
+		}
+		return
+	}
+	if f.value.CanSet() {
+		f.value.Set(reflect.Zero(f.value.Type()))
+	}
+}
+
 func (f *field) ShouldDiscard(o Options) bool {
 	return !f.ShouldKeep(o)
 }
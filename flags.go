@@ -0,0 +1,106 @@
+package labeler
+
+import (
+	"flag"
+	"reflect"
+)
+
+// BindFlags registers a flag on fs for each tagged field of v, a pointer to a
+// struct. The key of the field is used as the name of the flag, DocToken as its
+// usage and DefaultToken as its default, which is assigned to v immediately.
+// Flag values are converted as they are by Unmarshal.
+//
+// Indexed slices and arrays of structs, prefixed maps and the container are
+// not registered. Slices receive the values of every occurrence of their flag,
+// replacing their default. Like the Var methods of flag.FlagSet, BindFlags
+// panics if a flag is registered more than once.
+func BindFlags(fs *flag.FlagSet, v interface{}, opts ...Option) error {
+	lbl := newLabeler(opts)
+	return lbl.BindFlags(fs, v)
+}
+
+// BindFlags registers a flag on fs for each tagged field of v using the Options
+// provided to Labeler. See BindFlags.
+func (lbl *Labeler) BindFlags(fs *flag.FlagSet, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrInvalidValue
+	}
+	o := lbl.options
+	sub, err := walkSubject(rv, o)
+	if err != nil {
+		return err
+	}
+	o = o.FromTag(sub.containerTag())
+	for _, f := range sub.tagged {
+		if f.isIndexed || f.mapPrefix != "" {
+			continue
+		}
+		fv := &flagValue{f: f, o: o}
+		if f.HasDefault(o) {
+			if err := fv.unmarshal(nil); err != nil {
+				return err
+			}
+		}
+		fs.Var(fv, f.key, f.tag.Doc)
+	}
+	return nil
+}
+
+// flagValue is a flag.Value which converts the flag's value with the field's
+// unmarshaler.
+type flagValue struct {
+	f   *field
+	o   Options
+	set bool
+}
+
+// unmarshal unmarshals the field from kvs, which is populated with s if it is
+// not nil. Otherwise the field's default is applied.
+func (fv *flagValue) unmarshal(s *string) error {
+	kvs := newKeyValues()
+	if s != nil {
+		kvs.Set(fv.f.key, *s)
+	}
+	fv.f.op = OpUnmarshal
+	if err := fv.f.Unmarshal(&kvs, fv.o); err != nil {
+		return err
+	}
+	if fv.f.wasSet {
+		fv.f.Save()
+	}
+	return nil
+}
+
+func (fv *flagValue) String() string {
+	// flag calls String on a zero value to determine whether the default is the
+	// zero value
+	if fv == nil || fv.f == nil {
+		return ""
+	}
+	// as with the flags of the flag package, zero values are formatted as ""
+	// so that PrintDefaults omits them
+	if fv.f.value.IsZero() {
+		return ""
+	}
+	kvs := newKeyValues()
+	if err := fv.f.Marshal(&kvs, fv.o); err != nil {
+		return ""
+	}
+	kv, _ := kvs.Get(fv.f.key, false)
+	return kv.Value
+}
+
+func (fv *flagValue) Set(s string) error {
+	if !fv.set && fv.f.IsSlice() && fv.f.value.CanSet() {
+		// the first occurrence replaces the default
+		fv.f.value.Set(reflect.Zero(fv.f.value.Type()))
+	}
+	fv.set = true
+	return fv.unmarshal(&s)
+}
+
+// IsBoolFlag allows boolean flags to be set without a value, such as -debug
+func (fv *flagValue) IsBoolFlag() bool {
+	return fv.f != nil && fv.f.Kind() == reflect.Bool
+}
@@ -0,0 +1,95 @@
+package labeler
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SyntaxError occurs when the contents of a .env, .properties or INI file can
+// not be parsed.
+type SyntaxError struct {
+	// Format is the format of the file: "dotenv", "properties" or "ini"
+	Format string
+	// Line is the line on which the entry containing the error starts
+	Line int
+	Err  error
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: line %d: %v", err.Format, err.Line, err.Err)
+}
+
+func (err *SyntaxError) Unwrap() error {
+	return err.Err
+}
+
+// labelEntry is a marshaled label along with the field it belongs to, used by
+// the file formats which preserve the order of fields.
+type labelEntry struct {
+	Key   string
+	Value string
+	// Doc is the field's DocToken, set on the first entry of the field
+	Doc string
+	// Prefix is the key prefix of the field, such as "db." for the fields of a
+	// nested struct marked with PrefixToken. It is empty for the container.
+	Prefix string
+}
+
+// marshalEntries marshals v, returning its labels grouped by field in the
+// order the fields are declared, followed by the remaining labels sorted by
+// key. v does not need a container.
+func (lbl *Labeler) marshalEntries(v interface{}) ([]labelEntry, Options, error) {
+	o := lbl.options
+	o.containerOptional = true
+	kvs, err := lbl.marshal(v, o)
+	if err != nil {
+		return nil, o, err
+	}
+	sub, err := walkSubject(reflect.ValueOf(v), o)
+	if err != nil {
+		return nil, o, err
+	}
+	o = o.FromTag(sub.containerTag())
+	m := kvs.Map()
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]labelEntry, 0, len(keys))
+	written := map[string]bool{}
+	for _, f := range sub.tagged {
+		doc := f.tag.Doc
+		for _, key := range keys {
+			if written[key] || !f.ownsKey(key, o) {
+				continue
+			}
+			written[key] = true
+			entries = append(entries, labelEntry{Key: key, Value: m[key], Doc: doc, Prefix: f.keyPrefix})
+			doc = ""
+		}
+	}
+	for _, key := range keys {
+		if !written[key] {
+			entries = append(entries, labelEntry{Key: key, Value: m[key]})
+		}
+	}
+	return entries, o, nil
+}
+
+// ownsKey reports whether key, as marshaled, belongs to f.
+func (f *field) ownsKey(key string, o Options) bool {
+	switch {
+	case containsString(f.keys, key) || (f.legacyKey != "" && key == f.legacyKey):
+		return true
+	case f.mapPrefix != "":
+		return strings.HasPrefix(key, f.mapPrefix)
+	case f.isIndexed:
+		before, _, err := indexAffixes(o)
+		return err == nil && strings.HasPrefix(key, f.key+before)
+	}
+	return false
+}
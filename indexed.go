@@ -0,0 +1,197 @@
+package labeler
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	// see prefixmap.go regarding the initialization cycle
+	fieldMarshalers = append(marshalerFuncs{marshalIndexed}, fieldMarshalers...)
+	fieldUnmarshalers = append(unmarshalerFuncs{unmarshalIndexed}, fieldUnmarshalers...)
+}
+
+// IndexError occurs when the indices of labels for a slice or array of
+// structs are not sequential, starting at 0, or exceed the length of an array.
+type IndexError struct {
+	// Key is the label key containing the index
+	Key string
+	// Index is the index as it appears in Key
+	Index string
+	Err   error
+}
+
+func (err *IndexError) Error() string {
+	return fmt.Sprintf("%v: %q in %q", err.Err, err.Index, err.Key)
+}
+
+func (err *IndexError) Unwrap() error {
+	return err.Err
+}
+
+// indexAffixes splits Options.IndexFormat around its verb, returning the text
+// preceding and following the index.
+func indexAffixes(o Options) (string, string, error) {
+	parts := strings.Split(o.IndexFormat, "%d")
+	if len(parts) != 2 {
+		return "", "", NewOptionError("IndexFormat", "must contain %d exactly once")
+	}
+	return parts[0], parts[1] + o.PathDelimiter, nil
+}
+
+// indexedKeys groups the keys in kvs belonging to the elements of f by index.
+func (f *field) indexedKeys(kvs *keyValues, o Options) (map[int]*keyValues, []string, error) {
+	before, after, err := indexAffixes(o)
+	if err != nil {
+		return nil, nil, err
+	}
+	prefix := f.key + before
+	groups := map[int]*keyValues{}
+	keys := []string{}
+	for key, value := range kvs.Map() {
+		if len(key) <= len(prefix) || !(key[:len(prefix)] == prefix || (f.ignoreCase(o) && strings.EqualFold(key[:len(prefix)], prefix))) {
+			continue
+		}
+		rest := key[len(prefix):]
+		i := strings.Index(rest, after)
+		if i <= 0 {
+			continue
+		}
+		idx := rest[:i]
+		n, err := strconv.Atoi(idx)
+		if err != nil || n < 0 || strconv.Itoa(n) != idx {
+			return nil, nil, &IndexError{Key: key, Index: idx, Err: ErrInvalidIndex}
+		}
+		if f.isArray && n >= f.len {
+			return nil, nil, &IndexError{Key: key, Index: idx, Err: ErrIndexOutOfRange}
+		}
+		if _, ok := groups[n]; !ok {
+			nkvs := newKeyValues()
+			groups[n] = &nkvs
+		}
+		groups[n].SetFrom(rest[i+len(after):], value, key)
+		keys = append(keys, key)
+	}
+	indices := make([]int, 0, len(groups))
+	for n := range groups {
+		indices = append(indices, n)
+	}
+	sort.Ints(indices)
+	for i, n := range indices {
+		if i != n {
+			return nil, nil, &IndexError{Key: f.key + before + strconv.Itoa(i), Index: strconv.Itoa(i), Err: ErrIndexGap}
+		}
+	}
+	sort.Strings(keys)
+	return groups, keys, nil
+}
+
+// isIndexed reports whether r is a slice or array of structs, which are
+// marshaled with indexed keys such as "servers.0.host".
+func isIndexed(r reflected, o Options) bool {
+	if r.Topic() != fieldTopic || (!r.IsArray() && !r.IsSlice()) || r.IsElem() || r.ColType().Implements(stringeeType) || isPkgType(r.ColType()) {
+		return false
+	}
+	r.PrepCollection()
+	defer r.ResetCollection()
+	return r.IsStruct() && collectionUnmarshalers.Unmarshaler(r, o) == nil && collectionMarshalers.Marshaler(r, o) == nil
+}
+
+var unmarshalIndexed = func(r reflected, o Options) unmarshalFunc {
+	if !r.CanSet() || !isIndexed(r, o) {
+		return nil
+	}
+	r.(*field).isIndexed = true
+	return func(r reflected, kvs *keyValues, o Options) error {
+		f := r.(*field)
+		before, after, err := indexAffixes(o)
+		if err != nil {
+			return err
+		}
+		groups, keys, err := f.indexedKeys(kvs, o)
+		if err != nil {
+			return err
+		}
+		if len(groups) == 0 {
+			return f.validateAbsent(o)
+		}
+		prev := f.snapshotCollection()
+		col := f.indexedElems(len(groups), o)
+		for i := 0; i < len(groups); i++ {
+			if err := unmarshalStructElem(col.Index(i), groups[i], o); err != nil {
+				return nestFieldErrors(err, fmt.Sprintf("%s[%d]", f.path, i), f.key+before+strconv.Itoa(i)+after)
+			}
+			kvs.MarkUsedFrom(groups[i])
+		}
+		f.colValue.Set(col)
+		f.wasSet = true
+		if f.ShouldDiscard(o) {
+			for _, key := range keys {
+				kvs.Delete(key)
+			}
+		}
+		return f.validateUnmarshaledCollection(prev, "", o)
+	}
+}
+
+// indexedElems returns a copy of the collection of f, holding at least n
+// elements, for the elements at each index to be unmarshaled into. Elements are
+// merged by index, so the existing elements are kept unless f is replaced.
+// The copy is assigned to f only once every element has been unmarshaled so
+// that f is left untouched if one of them fails.
+func (f *field) indexedElems(n int, o Options) reflect.Value {
+	merges := f.merges(o)
+	col := reflect.New(f.colType).Elem()
+	if f.isArray {
+		if merges {
+			col.Set(f.colValue)
+		}
+	} else {
+		if merges && f.colValue.Len() > n {
+			n = f.colValue.Len()
+		}
+		col.Set(reflect.MakeSlice(f.colType, n, n))
+		if merges {
+			reflect.Copy(col, f.colValue)
+		}
+	}
+	if !merges || f.colType.Elem().Kind() != reflect.Ptr {
+		return col
+	}
+	// pointers to existing elements are copied so that they are not modified
+	// in place
+	for i := 0; i < col.Len(); i++ {
+		if elem := col.Index(i); !elem.IsNil() {
+			cp := reflect.New(elem.Type().Elem())
+			cp.Elem().Set(elem.Elem())
+			elem.Set(cp)
+		}
+	}
+	return col
+}
+
+var marshalIndexed = func(r reflected, o Options) marshalFunc {
+	if !isIndexed(r, o) {
+		return nil
+	}
+	return func(r reflected, kvs *keyValues, o Options) error {
+		f := r.(*field)
+		if err := f.validateCollection("", o); err != nil {
+			return err
+		}
+		before, after, err := indexAffixes(o)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < f.colValue.Len(); i++ {
+			prefix := f.key + before + strconv.Itoa(i) + after
+			if err := marshalStructElem(f.colValue.Index(i), prefix, kvs, o); err != nil {
+				return nestFieldErrors(err, fmt.Sprintf("%s[%d]", f.path, i), prefix)
+			}
+		}
+		return nil
+	}
+}
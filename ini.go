@@ -0,0 +1,161 @@
+package labeler
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// UnmarshalINI parses the INI file read from r and unmarshals its entries
+// into v. The keys of a section are prefixed with the section's name followed
+// by Options.PathDelimiter, so that "host" in "[db]" is unmarshaled as
+// "db.host", such as into a nested struct marked with PrefixToken. Keys are
+// separated from values by "=" or ":". Lines starting with ";" or "#" are
+// comments, as is the remainder of an unquoted value following " ;" or " #".
+// Values may be double quoted, in which case they may contain the escapes \",
+// \\, \n, \r and \t. v does not need a container.
+func UnmarshalINI(r io.Reader, v interface{}, opts ...Option) error {
+	lbl := newLabeler(opts)
+	return lbl.UnmarshalINI(r, v)
+}
+
+// MarshalINI writes v to w as an INI file. The fields of nested structs
+// marked with PrefixToken are written to a section named after the prefix,
+// while all other keys precede the first section. Keys are written in the
+// order their fields are declared, preceded by the field's DocToken as a
+// comment, followed by the remaining labels sorted by key.
+func MarshalINI(w io.Writer, v interface{}, opts ...Option) error {
+	lbl := newLabeler(opts)
+	return lbl.MarshalINI(w, v)
+}
+
+// UnmarshalINI parses the INI file read from r into v using the Options
+// provided to Labeler. See UnmarshalINI.
+func (lbl *Labeler) UnmarshalINI(r io.Reader, v interface{}) error {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	o := lbl.options
+	o.containerOptional = true
+	m, err := parseINI(string(src), o)
+	if err != nil {
+		return err
+	}
+	return lbl.unmarshal(m, v, o)
+}
+
+// MarshalINI writes v to w as an INI file using the Options provided to
+// Labeler. See MarshalINI.
+func (lbl *Labeler) MarshalINI(w io.Writer, v interface{}) error {
+	entries, o, err := lbl.marshalEntries(v)
+	if err != nil {
+		return err
+	}
+	delim := o.PathDelimiter
+	global := []labelEntry{}
+	sections := map[string][]labelEntry{}
+	names := []string{}
+	for _, e := range entries {
+		// prefixes with a field level delimiter can not be read back as a
+		// section and are written in full
+		if e.Prefix == "" || !strings.HasSuffix(e.Prefix, delim) {
+			global = append(global, e)
+			continue
+		}
+		name := strings.TrimSuffix(e.Prefix, delim)
+		if _, ok := sections[name]; !ok {
+			names = append(names, name)
+		}
+		e.Key = strings.TrimPrefix(e.Key, e.Prefix)
+		sections[name] = append(sections[name], e)
+	}
+
+	var b strings.Builder
+	writeINIEntries(&b, global)
+	for _, name := range names {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("[" + name + "]\n")
+		writeINIEntries(&b, sections[name])
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func writeINIEntries(b *strings.Builder, entries []labelEntry) {
+	for i, e := range entries {
+		if e.Doc != "" {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("; " + e.Doc + "\n")
+		}
+		b.WriteString(e.Key + " = " + quoteINI(e.Value) + "\n")
+	}
+}
+
+// quoteINI double quotes s if it contains leading or trailing whitespace,
+// quotes, comment characters or control characters.
+func quoteINI(s string) string {
+	if s == "" || (s == strings.TrimSpace(s) && !strings.ContainsAny(s, "\";#\\\r\n\t")) {
+		return s
+	}
+	return `"` + iniEscaper.Replace(s) + `"`
+}
+
+var iniEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+var iniUnescaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n", `\r`, "\r", `\t`, "\t")
+
+func parseINI(src string, o Options) (map[string]string, error) {
+	lines := strings.Split(strings.Replace(src, "\r\n", "\n", -1), "\n")
+	m := map[string]string{}
+	prefix := ""
+	for i, line := range lines {
+		n := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return nil, &SyntaxError{Format: "ini", Line: n, Err: ErrSyntax}
+			}
+			if trailing := strings.TrimSpace(line[end+1:]); trailing != "" && trailing[0] != ';' && trailing[0] != '#' {
+				return nil, &SyntaxError{Format: "ini", Line: n, Err: ErrSyntax}
+			}
+			prefix = ""
+			if name := strings.TrimSpace(line[1:end]); name != "" {
+				prefix = name + o.PathDelimiter
+			}
+			continue
+		}
+		eq := strings.IndexAny(line, "=:")
+		if eq <= 0 {
+			return nil, &SyntaxError{Format: "ini", Line: n, Err: ErrSyntax}
+		}
+		key := strings.TrimSpace(line[:eq])
+		value := strings.TrimSpace(line[eq+1:])
+		if value != "" && value[0] == '"' {
+			end := closingQuote(value[1:], '"')
+			if end < 0 {
+				return nil, &SyntaxError{Format: "ini", Line: n, Err: ErrUnterminatedQuote}
+			}
+			if trailing := strings.TrimSpace(value[end+2:]); trailing != "" && trailing[0] != ';' && trailing[0] != '#' {
+				return nil, &SyntaxError{Format: "ini", Line: n, Err: ErrSyntax}
+			}
+			value = iniUnescaper.Replace(value[1 : end+1])
+		} else {
+			for _, comment := range []string{" ;", "\t;", " #", "\t#"} {
+				if j := strings.Index(value, comment); j >= 0 {
+					value = strings.TrimSpace(value[:j])
+				}
+			}
+		}
+		m[prefix+key] = value
+	}
+	return m, nil
+}
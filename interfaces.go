@@ -62,6 +62,13 @@ type MarshalerWithOpts interface {
 	MarshalLabels(o Options) (map[string]string, error)
 }
 
+// Generated is implemented by types with MarshalLabels and UnmarshalLabels
+// methods generated by labeler-gen. The tagged fields of a Generated type are
+// not parsed by Labeler; its generated methods are called instead.
+type Generated interface {
+	GeneratedLabels()
+}
+
 // Stringee is implemented by any value that has a FromString method,
 // which parses the “native” format for that value from a string and
 // returns a bool value to indicate success (true) or failure (false)
@@ -94,11 +101,13 @@ var unmarshalerType = reflect.TypeOf(new(Unmarshaler)).Elem()
 var unmarshalerWithOptsType = reflect.TypeOf(new(UnmarshalerWithOpts)).Elem()
 var marshalerType = reflect.TypeOf(new(Marshaler)).Elem()
 var marshalerWithOptsType = reflect.TypeOf(new(MarshalerWithOpts)).Elem()
+var generatedType = reflect.TypeOf(new(Generated)).Elem()
 var stringeeType = reflect.TypeOf(new(Stringee)).Elem()
 var textUnmarshalerType = reflect.TypeOf(new(TextUnmarshaler)).Elem()
 var textMarshalerType = reflect.TypeOf((new(TextMarshaler))).Elem()
 var stringerType = reflect.TypeOf(new(fmt.Stringer)).Elem()
 var stringType = reflect.TypeOf("")
 var mapType reflect.Type = reflect.MapOf(stringType, stringType)
+var multiMapType reflect.Type = reflect.MapOf(stringType, reflect.SliceOf(stringType))
 var timeType = reflect.TypeOf(time.Time{})
 var durationType = func() reflect.Type { var d time.Duration; return reflect.TypeOf(d) }()
@@ -1,23 +1,36 @@
 package labeler
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 type keyvalue struct {
 	Key   string
 	Value string
+	// Values holds every value of Key if it is multi-valued
+	Values []string
 }
 
 type keyValues struct {
 	lookup map[string]*keyvalue
 	lcase  map[string]*keyvalue
 	m      map[string]string
+	// used holds the keys which have been retrieved with Get, directly or
+	// through a nested keyValues (see MarkUsedFrom)
+	used map[string]bool
+	// sources maps keys to the keys of an enclosing keyValues which they were
+	// derived from, such as "host" to "servers.0.host"
+	sources map[string]string
 }
 
 func newKeyValues() keyValues {
 	kvs := keyValues{
-		lookup: make(map[string]*keyvalue),
-		lcase:  make(map[string]*keyvalue),
-		m:      make(map[string]string),
+		lookup:  make(map[string]*keyvalue),
+		lcase:   make(map[string]*keyvalue),
+		m:       make(map[string]string),
+		used:    make(map[string]bool),
+		sources: make(map[string]string),
 	}
 	return kvs
 }
@@ -31,12 +44,24 @@ func (kvs *keyValues) Get(key string, ignorecase bool) (keyvalue, bool) {
 		kv, ok = kvs.lookup[key]
 	}
 	if ok {
+		kvs.used[kv.Key] = true
 		return *kv, ok
 	}
 	return keyvalue{}, ok
 
 }
 
+// GetFirst returns the value of the first of keys that is present along with
+// its index in keys.
+func (kvs *keyValues) GetFirst(keys []string, ignorecase bool) (keyvalue, int, bool) {
+	for i, key := range keys {
+		if kv, ok := kvs.Get(key, ignorecase); ok {
+			return kv, i, true
+		}
+	}
+	return keyvalue{}, -1, false
+}
+
 func (kvs *keyValues) Set(key string, v string) {
 	kv := &keyvalue{Key: key, Value: v}
 	kvs.lookup[key] = kv
@@ -44,10 +69,60 @@ func (kvs *keyValues) Set(key string, v string) {
 	kvs.m[key] = v
 }
 
+// SetValues sets key to value while retaining each of its values.
+func (kvs *keyValues) SetValues(key string, value string, values []string) {
+	kvs.Set(key, value)
+	kvs.lookup[key].Values = values
+}
+
 func (kvs *keyValues) Map() map[string]string {
 	return kvs.m
 }
 
+// MultiMap returns the values of each key, including single-valued keys.
+func (kvs *keyValues) MultiMap() map[string][]string {
+	res := make(map[string][]string, len(kvs.m))
+	for key, kv := range kvs.lookup {
+		if kv.Values != nil {
+			res[key] = kv.Values
+		} else {
+			res[key] = []string{kv.Value}
+		}
+	}
+	return res
+}
+
+// SetFrom sets key to v, recording source as the key of an enclosing keyValues
+// which it was derived from. See MarkUsedFrom.
+func (kvs *keyValues) SetFrom(key string, v string, source string) {
+	kvs.Set(key, v)
+	kvs.sources[key] = source
+}
+
+// MarkUsedFrom marks the source keys of the consumed keys of nested as used.
+func (kvs *keyValues) MarkUsedFrom(nested *keyValues) {
+	for key := range nested.used {
+		if source, ok := nested.sources[key]; ok {
+			kvs.used[source] = true
+		}
+	}
+}
+
+// IsUsed reports whether key has been consumed.
+func (kvs *keyValues) IsUsed(key string) bool {
+	return kvs.used[key]
+}
+
+// Keys returns the keys of kvs, sorted.
+func (kvs *keyValues) Keys() []string {
+	keys := make([]string, 0, len(kvs.m))
+	for key := range kvs.m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func (kvs *keyValues) Delete(key string) {
 	delete(kvs.m, key)
 	delete(kvs.lookup, key)
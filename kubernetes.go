@@ -0,0 +1,136 @@
+package labeler
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	kubernetesNameMaxLength   = 63
+	kubernetesPrefixMaxLength = 253
+	// kubernetesAnnotationsMaxSize is the limit on the total size of the keys
+	// and values of annotations
+	kubernetesAnnotationsMaxSize = 256 * (1 << 10)
+)
+
+var (
+	kubernetesNamePattern   = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+	kubernetesValuePattern  = regexp.MustCompile(`^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$`)
+	kubernetesPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// KubernetesError is the Err of a FieldError when a key or value does not
+// satisfy the rules of Options.KubernetesMode.
+type KubernetesError struct {
+	Key   string
+	Value string
+	// Reason describes the rule which was broken
+	Reason string
+}
+
+func (err *KubernetesError) Error() string {
+	return fmt.Sprintf("%q: %s", err.Key, err.Reason)
+}
+
+func (err *KubernetesError) Unwrap() error {
+	return ErrKubernetes
+}
+
+// kubernetesKeyReason returns the reason key is not a valid label or
+// annotation key, or "" if it is valid.
+func kubernetesKeyReason(key string) string {
+	name := key
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		switch {
+		case prefix == "":
+			return "prefix must not be empty"
+		case len(prefix) > kubernetesPrefixMaxLength:
+			return fmt.Sprintf("prefix must be no more than %d characters", kubernetesPrefixMaxLength)
+		case !kubernetesPrefixPattern.MatchString(prefix):
+			return "prefix must be a lowercase DNS subdomain, such as \"example.com\""
+		}
+	}
+	switch {
+	case name == "":
+		return "name must not be empty"
+	case len(name) > kubernetesNameMaxLength:
+		return fmt.Sprintf("name must be no more than %d characters", kubernetesNameMaxLength)
+	case !kubernetesNamePattern.MatchString(name):
+		return "name must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character"
+	}
+	return ""
+}
+
+// kubernetesValueReason returns the reason value is not a valid label value,
+// or "" if it is valid.
+func kubernetesValueReason(value string) string {
+	switch {
+	case len(value) > kubernetesNameMaxLength:
+		return fmt.Sprintf("value must be no more than %d characters", kubernetesNameMaxLength)
+	case !kubernetesValuePattern.MatchString(value):
+		return "value must be empty or consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character"
+	}
+	return ""
+}
+
+// validateKubernetes checks the keys and values of kvs against the rules of
+// Options.KubernetesMode. Violations are attributed to the field which owns
+// the key, the container or, lacking both, to the key alone.
+func (sub *subject) validateKubernetes(kvs *keyValues, op Op, o Options) error {
+	if o.KubernetesMode == KubernetesOff {
+		return nil
+	}
+	m := kvs.Map()
+	keys := make([]string, 0, len(m))
+	size := 0
+	for key, value := range m {
+		keys = append(keys, key)
+		size += len(key) + len(value)
+	}
+	sort.Strings(keys)
+
+	fieldErrs := []*FieldError{}
+	for _, key := range keys {
+		value := m[key]
+		reason := kubernetesKeyReason(key)
+		if reason == "" && o.KubernetesMode == KubernetesLabels {
+			reason = kubernetesValueReason(value)
+		}
+		if reason != "" {
+			fieldErrs = append(fieldErrs, sub.kubernetesErr(key, value, reason, op, o))
+		}
+	}
+	if o.KubernetesMode == KubernetesAnnotations && size > kubernetesAnnotationsMaxSize {
+		reason := fmt.Sprintf("total size of annotations must be no more than %d bytes, was %d", kubernetesAnnotationsMaxSize, size)
+		fieldErrs = append(fieldErrs, sub.kubernetesErr("", "", reason, op, o))
+	}
+	if len(fieldErrs) > 0 {
+		return NewParsingError(fieldErrs)
+	}
+	return nil
+}
+
+func (sub *subject) kubernetesErr(key, value, reason string, op Op, o Options) *FieldError {
+	err := &KubernetesError{Key: key, Value: value, Reason: reason}
+	owner := sub.container
+	for _, f := range sub.tagged {
+		if key != "" && f.ownsKey(key, o) {
+			owner = f
+			break
+		}
+	}
+	var fe *FieldError
+	if owner != nil {
+		owner.op = op
+		fe = newFieldError(owner, err)
+	} else {
+		fe = NewFieldError("", err)
+		fe.Op = op
+	}
+	fe.Key, fe.Value = key, value
+	return fe
+}
@@ -1,15 +1,22 @@
 // Package labeler marshals and unmarshals map[string]string utilizing struct tags.
 package labeler
 
-// Labeler Marshals and Unmarshals map[string]string based on struct tags and options
+import "reflect"
+
+// Labeler Marshals and Unmarshals map[string]string based on struct tags and options.
+// The tags and types of each struct are parsed once per Labeler and reused for
+// subsequent calls. A Labeler is safe for concurrent use.
 type Labeler struct {
 	options Options
+	plans   *planCache
 }
 
+var defaultLabeler = NewLabeler()
+
 // Unmarshal parses labels and unmarshals them into v. See README.md for
 // available options for input and v.
 func Unmarshal(input interface{}, v interface{}, opts ...Option) error {
-	lbl := NewLabeler(opts...)
+	lbl := newLabeler(opts)
 	return lbl.Unmarshal(input, v)
 }
 
@@ -20,19 +27,41 @@ func Unmarshal(input interface{}, v interface{}, opts ...Option) error {
 // values as they are just present to ensure that all labels are stored, regardless
 // of unmarshaling.
 func Marshal(v interface{}, opts ...Option) (l map[string]string, err error) {
-	lbl := NewLabeler(opts...)
+	lbl := newLabeler(opts)
 	return lbl.Marshal(v)
 }
 
+// MarshalValues marshals v as Marshal does, retaining each value of
+// multi-valued labels such as the elements of slices and the values of
+// map[string][]string containers. It is suited for http.Header, url.Values and
+// similar outputs.
+func MarshalValues(v interface{}, opts ...Option) (map[string][]string, error) {
+	lbl := newLabeler(opts)
+	return lbl.MarshalValues(v)
+}
+
 // NewLabeler returns a new Labeler instance based upon Options (if any) provided.
 func NewLabeler(opts ...Option) Labeler {
 	o := newOptions(opts)
 	lbl := Labeler{
 		options: o,
+		plans:   newPlanCache(),
 	}
 	return lbl
 }
 
+// newLabeler returns the shared default Labeler if there are no opts so that
+// the package level Marshal and Unmarshal benefit from cached plans. Otherwise
+// the Labeler is used once and so it is returned without a planCache,
+// building subjects directly rather than compiling plans which would be
+// discarded.
+func newLabeler(opts []Option) *Labeler {
+	if len(opts) == 0 {
+		return &defaultLabeler
+	}
+	return &Labeler{options: newOptions(opts)}
+}
+
 // ValidateOptions checks the options provided
 func (lbl Labeler) ValidateOptions() error {
 	return lbl.options.Validate()
@@ -40,8 +69,16 @@ func (lbl Labeler) ValidateOptions() error {
 
 //Unmarshal input into v using the Options provided to Labeler
 func (lbl *Labeler) Unmarshal(input interface{}, v interface{}) error {
-	o := lbl.options
-	sub, err := newSubject(v, o)
+	return lbl.unmarshal(input, v, lbl.options)
+}
+
+func (lbl *Labeler) unmarshal(input interface{}, v interface{}, o Options) error {
+	o.plans = lbl.plans
+	target, commit := v, func() {}
+	if o.Transactional {
+		target, commit = scratch(v)
+	}
+	sub, err := lbl.subject(target)
 	if err != nil {
 		return err
 	}
@@ -54,17 +91,42 @@ func (lbl *Labeler) Unmarshal(input interface{}, v interface{}) error {
 	if err != nil {
 		return err
 	}
-	return sub.Unmarshal(&kvs, o)
+	if err := sub.Unmarshal(&kvs, o); err != nil {
+		return err
+	}
+	commit()
+	return nil
 }
 
 // Marshal v into map[string]string using the Options provided to Labeler
 func (lbl *Labeler) Marshal(v interface{}) (map[string]string, error) {
-	o := lbl.options
+	kvs, err := lbl.marshal(v, lbl.options)
+	return kvs.Map(), err
+}
+
+// MarshalValues marshals v into map[string][]string using the Options provided
+// to Labeler. See MarshalValues.
+func (lbl *Labeler) MarshalValues(v interface{}) (map[string][]string, error) {
+	kvs, err := lbl.marshal(v, lbl.options)
+	return kvs.MultiMap(), err
+}
+
+func (lbl *Labeler) marshal(v interface{}, o Options) (keyValues, error) {
+	o.plans = lbl.plans
 	kvs := newKeyValues()
-	sub, err := newSubject(v, o)
+	sub, err := lbl.subject(v)
 	if err != nil {
-		return kvs.Map(), err
+		return kvs, err
 	}
 	err = sub.Marshal(&kvs, o)
-	return kvs.Map(), err
+	return kvs, err
+}
+
+// subject binds v to the cached plan for its type. See planCache.subject.
+func (lbl *Labeler) subject(v interface{}) (subject, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return subject{}, ErrInvalidValue
+	}
+	return lbl.plans.subject(rv, lbl.options)
 }
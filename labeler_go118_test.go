@@ -0,0 +1,38 @@
+//go:build go1.18
+// +build go1.18
+
+package labeler
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type WithNetip struct {
+	Addr     netip.Addr        `label:"addr"`
+	Prefix   netip.Prefix      `label:"prefix"`
+	Allowed  []netip.Prefix    `label:"allowed"`
+	Gateways [1]*netip.Addr    `label:"gateways"`
+	Labels   map[string]string `label:"*"`
+}
+
+func TestNetipTypes(t *testing.T) {
+	m := map[string]string{
+		"addr":     "2001:db8::1",
+		"prefix":   "192.168.0.0/24",
+		"allowed":  "10.0.0.0/8,fd00::/8",
+		"gateways": "10.0.0.1",
+	}
+	v := WithNetip{}
+	assert.NoError(t, Unmarshal(m, &v))
+	assert.Equal(t, netip.MustParseAddr("2001:db8::1"), v.Addr)
+	assert.Equal(t, 24, v.Prefix.Bits())
+	assert.Len(t, v.Allowed, 2)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), *v.Gateways[0])
+
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, m, res)
+}
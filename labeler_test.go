@@ -1,9 +1,21 @@
 package labeler
 
 import (
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -305,6 +317,21 @@ func TestEnum(t *testing.T) {
 
 }
 
+type WithIgnoreCase struct {
+	Color  string            `label:"color,ignorecase"`
+	Shape  string            `label:"shape"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestIgnoreCaseToken(t *testing.T) {
+	v := &WithIgnoreCase{}
+	err := Unmarshal(map[string]string{"COLOR": "red", "SHAPE": "round"}, v, OptCaseSensitive())
+	assert.NoError(t, err)
+	assert.Equal(t, "red", v.Color)
+	assert.Equal(t, "", v.Shape)
+	assert.Equal(t, "round", v.Labels["SHAPE"])
+}
+
 type InvalidDueToNonaddressableContainer struct {
 	Name   string            `label:"name"`
 	labels map[string]string `label:"*"`
@@ -642,93 +669,2044 @@ func TestIgnoreUnaccessibleFields(t *testing.T) {
 	assert.Equal(t, l["public"], priv.Public)
 }
 
-// type WithValidation struct {
-// 	Name          string            `label:"name"`
-// 	Enum          MyEnum            `label:"enum,required"`
-// 	RequiredField string            `label:"required_field,required"`
-// 	Defaulted     string            `label:"defaulted,default:default value"`
-// 	Labels        map[string]string `label:"*"`
-// }
-
-// func TestLabeleeWithValidation(t *testing.T) {
-// 	l := StructWithLabels{
-// 		Labels: map[string]string{
-// 			"name": "my name",
-// 			"enum": "X",
-// 		},
-// 	}
-// 	v := &WithValidation{}
-// 	err := Unmarshal(l, v)
-// 	assert.Error(t, err, "should contain errors")
-// 	var e *ParsingError
-// 	if errors.As(err, &e) {
-// 		assert.Len(t, e.Errors, 2)
-// 	} else {
-// 		assert.Fail(t, "error should be a parsing error")
-// 	}
-// 	assert.Equal(t, "my name", v.Name)
-// 	assert.Equal(t, EnumUnknown, v.Enum)
-// }
-
-// type InvalidDueToMissingLabels struct {
-// 	Name string `label:"name,required"`
-// }
-
-// type InvalidDueMyEnumErr struct {
-// 	Enum MyEnum `label:"enum,required"`
-// }
-
-// func TestInvalidDueToMyEnumReturningError(t *testing.T) {
-// 	l := StructWithLabels{
-// 		Labels: map[string]string{
-// 			"enum": "Invalid",
-// 		},
-// 	}
-
-// 	inv := &InvalidDueMyEnumErr{}
-// 	err := Unmarshal(l, inv)
-// 	assert.Error(t, err, "Should have thrown an error")
-// 	assert.Error(t, err)
-// 	if !errors.Is(err, ErrParsing) {
-// 		assert.Fail(t, "Error should be ErrInvalidValue")
-// 	}
-// 	var parsingError *ParsingError
-// 	if errors.As(err, &parsingError) {
-// 		assert.Equal(t, 1, len(parsingError.Errors))
-// 		fieldErr := parsingError.Errors[0]
-// 		fmt.Println(fieldErr)
-// 		if !errors.Is(fieldErr, ErrExampleInvalidEnum) {
-// 			assert.Fail(t, "Error should be ErrExampleInvalidEnum")
-// 		} else {
-// 			assert.Equal(t, "Enum", fieldErr.Field)
-// 		}
-// 	} else {
-// 		assert.Fail(t, "Error should be a ParsingError")
-// 	}
-// 	t.Log(err)
-// }
-
-// type InvalidDueMultipleRequiredFields struct {
-// 	Enum MyEnum `label:"enum,required"`
-// 	Name string `label:"name,required"`
-// }
-
-// func TestInvalidDueToMultipleRequiredFields(t *testing.T) {
-// 	l := StructWithLabels{
-// 		Labels: map[string]string{},
-// 	}
-
-// 	inv := &InvalidDueMultipleRequiredFields{}
-// 	err := Unmarshal(l, inv)
-// 	assert.Error(t, err, "Should have thrown an error")
-// 	assert.Error(t, err)
-
-// 	var parsingError *ParsingError
-// 	if errors.As(err, &parsingError) {
-// 		assert.Equal(t, 2, len(parsingError.Errors))
-// 	} else {
-// 		assert.Fail(t, "Error should be a ParsingError")
-// 	}
-
-// 	t.Log(err)
-// }
+func TestLabelerConcurrentUse(t *testing.T) {
+	lbl := NewLabeler()
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v := &NumberBaseStruct{}
+			in := map[string]string{"binaryint1": strconv.FormatInt(int64(i), 2)}
+			if err := lbl.Unmarshal(in, v); err != nil {
+				errs <- err
+				return
+			}
+			if v.BinaryInt1 != i {
+				errs <- fmt.Errorf("expected %d, got %d", i, v.BinaryInt1)
+				return
+			}
+			res, err := lbl.Marshal(v)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if res["binaryint1"] != in["binaryint1"] {
+				errs <- fmt.Errorf("expected %s, got %s", in["binaryint1"], res["binaryint1"])
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestLabelerReusesPlanAcrossValues(t *testing.T) {
+	lbl := NewLabeler()
+	a := &WithNestedStructAsPtr{}
+	b := &WithNestedStructAsPtr{}
+	assert.NoError(t, lbl.Unmarshal(map[string]string{"subfield": "a"}, a))
+	assert.NoError(t, lbl.Unmarshal(map[string]string{"subfield": "b"}, b))
+	if assert.NotNil(t, a.Nested) && assert.NotNil(t, b.Nested) {
+		assert.Equal(t, "a", a.Nested.SubField)
+		assert.Equal(t, "b", b.Nested.SubField)
+	}
+}
+
+func TestUnmarshalIntoNilContainer(t *testing.T) {
+	v := &ExampleWithEnum{}
+	err := Unmarshal(map[string]string{"enum": "ValueA", "other": "value"}, v)
+	assert.NoError(t, err)
+	assert.Equal(t, EnumValA, v.Enum)
+	assert.Equal(t, "value", v.Labels["other"])
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	lbl := NewLabeler()
+	in := map[string]string{
+		"name":     "Archer",
+		"enum":     "ValueB",
+		"int":      "123456789",
+		"duration": "1s",
+		"float64":  "1.1234567890",
+		"time":     "09/26/2020 10:10PM",
+		"bool":     "true",
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v := &Example{}
+		if err := lbl.Unmarshal(in, v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	lbl := NewLabeler()
+	v := &Example{
+		Name:     "Archer",
+		Enum:     EnumValB,
+		Int:      123456789,
+		Duration: time.Second,
+		Float64:  1.123456789,
+		Time:     time.Date(2020, time.September, 26, 22, 10, 0, 0, time.UTC),
+		Bool:     true,
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := lbl.Marshal(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkUnmarshalWithOptions covers the package level Unmarshal with
+// Options, which builds a Labeler for each call.
+func BenchmarkUnmarshalWithOptions(b *testing.B) {
+	in := map[string]string{
+		"name":     "Archer",
+		"enum":     "ValueB",
+		"int":      "123456789",
+		"duration": "1s",
+		"float64":  "1.1234567890",
+		"time":     "09/26/2020 10:10PM",
+		"bool":     "true",
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v := &Example{}
+		if err := Unmarshal(in, v, OptKeepLabels()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMarshalWithOptions covers the package level Marshal with Options.
+func BenchmarkMarshalWithOptions(b *testing.B) {
+	v := &Example{
+		Name:     "Archer",
+		Enum:     EnumValB,
+		Int:      123456789,
+		Duration: time.Second,
+		Float64:  1.123456789,
+		Time:     time.Date(2020, time.September, 26, 22, 10, 0, 0, time.UTC),
+		Bool:     true,
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Marshal(v, OptKeepLabels()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type WithMultipleInvalidFields struct {
+	First  string `label:""`
+	Second string `label:"second"`
+	third  string `label:"third"`
+	Nested struct {
+		fourth string `label:"fourth"`
+	}
+	Fifth  string            `label:"fifth,base:x"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestParsingErrorsAreInDeclarationOrder(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		err := Unmarshal(map[string]string{}, &WithMultipleInvalidFields{})
+		var pErr *ParsingError
+		if !errors.As(err, &pErr) {
+			assert.Fail(t, "err should be a ParsingError")
+			return
+		}
+		fields := []string{}
+		for _, e := range pErr.Errors {
+			fields = append(fields, e.Field)
+		}
+		assert.Equal(t, []string{"First", "third", "fourth", "Fifth"}, fields)
+	}
+}
+
+func TestTaggedFieldsAreInDeclarationOrder(t *testing.T) {
+	sub, err := newSubject(&WithNested{}, newOptions(nil))
+	assert.NoError(t, err)
+	keys := []string{}
+	for _, f := range sub.tagged {
+		keys = append(keys, f.key)
+	}
+	assert.Equal(t, []string{"subfield", "parentfield"}, keys)
+	if assert.NotNil(t, sub.container) {
+		assert.Equal(t, "Labels", sub.container.Path())
+	}
+}
+
+type WithMultipleContainers struct {
+	Labels map[string]string `label:"*"`
+	Other  map[string]string `label:"*"`
+}
+
+func TestMultipleContainers(t *testing.T) {
+	err := Unmarshal(map[string]string{}, &WithMultipleContainers{})
+	assert.True(t, errors.Is(err, ErrMultipleContainers))
+}
+
+type WithUintBase struct {
+	Mode   uint16            `label:"mode,uintbase:8"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestMarshalUintBase(t *testing.T) {
+	v := WithUintBase{Mode: 0755}
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "755", res["mode"])
+	v = WithUintBase{}
+	assert.NoError(t, Unmarshal(res, &v))
+	assert.Equal(t, uint16(0755), v.Mode)
+}
+
+type WithEmptyTokens struct {
+	Included string            `label:"included,includeempty"`
+	Omitted  string            `label:"omitted"`
+	Labels   map[string]string `label:"*"`
+}
+
+func TestMarshalEmptyTokens(t *testing.T) {
+	res, err := Marshal(&WithEmptyTokens{})
+	assert.NoError(t, err)
+	assert.Contains(t, res, "included")
+	assert.NotContains(t, res, "omitted")
+}
+
+func TestMarshalFieldsTakePrecedence(t *testing.T) {
+	v := WithEmptyTokens{}
+	assert.NoError(t, Unmarshal(map[string]string{"omitted": "old", "other": "value"}, &v))
+	v.Omitted = "new"
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", res["omitted"])
+	assert.Equal(t, "value", res["other"])
+}
+
+type TestingSliceOfTypes struct {
+	Enums     []MyEnum          `label:"enums"`
+	EnumPtrs  []*MyEnum         `label:"enum_ptrs"`
+	Durations []time.Duration   `label:"durations"`
+	Dates     [2]time.Time      `label:"dates,format:2006-01-02"`
+	Labels    map[string]string `label:"*"`
+}
+
+var sliceOfTypesLabels = map[string]string{
+	"enums":     "ValueA,ValueB",
+	"enum_ptrs": "ValueB,ValueA",
+	"durations": "1s,2m0s",
+	"dates":     "2020-01-02,2021-03-04",
+}
+
+func TestMarshalSliceOfTypes(t *testing.T) {
+	a, b := EnumValB, EnumValA
+	v := TestingSliceOfTypes{
+		Enums:     []MyEnum{EnumValA, EnumValB},
+		EnumPtrs:  []*MyEnum{&a, &b},
+		Durations: []time.Duration{time.Second, 2 * time.Minute},
+		Dates: [2]time.Time{
+			time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, time.March, 4, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, sliceOfTypesLabels, res)
+}
+
+func TestUnmarshalSliceOfTypes(t *testing.T) {
+	var v TestingSliceOfTypes
+	assert.NoError(t, Unmarshal(sliceOfTypesLabels, &v))
+	assert.Equal(t, []MyEnum{EnumValA, EnumValB}, v.Enums)
+	if assert.Len(t, v.EnumPtrs, 2) {
+		assert.Equal(t, EnumValB, *v.EnumPtrs[0])
+		assert.Equal(t, EnumValA, *v.EnumPtrs[1])
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Minute}, v.Durations)
+	assert.Equal(t, 2021, v.Dates[1].Year())
+}
+
+func BenchmarkNewSubject(b *testing.B) {
+	o := newOptions(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := newSubject(&Example{}, o); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type WithValidation struct {
+	Name          string            `label:"name"`
+	Enum          MyEnum            `label:"enum,required"`
+	RequiredField string            `label:"required_field,required"`
+	Defaulted     string            `label:"defaulted,default:default value"`
+	Labels        map[string]string `label:"*"`
+}
+
+func TestLabeleeWithValidation(t *testing.T) {
+	l := StructWithLabels{
+		Labels: map[string]string{
+			"name": "my name",
+			"enum": "X",
+		},
+	}
+	v := &WithValidation{}
+	err := Unmarshal(l, v)
+	assert.Error(t, err, "should contain errors")
+	var e *ParsingError
+	if errors.As(err, &e) {
+		assert.Len(t, e.Errors, 2)
+	} else {
+		assert.Fail(t, "error should be a parsing error")
+	}
+	assert.Equal(t, "my name", v.Name)
+	assert.Equal(t, EnumUnknown, v.Enum)
+}
+
+type InvalidDueToMissingLabels struct {
+	Name   string            `label:"name,required"`
+	Labels map[string]string `label:"*"`
+}
+
+type InvalidDueMyEnumErr struct {
+	Enum   MyEnum            `label:"enum,required"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestInvalidDueToMyEnumReturningError(t *testing.T) {
+	l := StructWithLabels{
+		Labels: map[string]string{
+			"enum": "Invalid",
+		},
+	}
+
+	inv := &InvalidDueMyEnumErr{}
+	err := Unmarshal(l, inv)
+	assert.Error(t, err, "Should have thrown an error")
+	assert.Error(t, err)
+	if !errors.Is(err, ErrParsing) {
+		assert.Fail(t, "Error should be ErrInvalidValue")
+	}
+	var parsingError *ParsingError
+	if errors.As(err, &parsingError) {
+		assert.Equal(t, 1, len(parsingError.Errors))
+		fieldErr := parsingError.Errors[0]
+		if !errors.Is(fieldErr, ErrExampleInvalidEnum) {
+			assert.Fail(t, "Error should be ErrExampleInvalidEnum")
+		} else {
+			assert.Equal(t, "Enum", fieldErr.Field)
+			assert.Equal(t, "Enum", fieldErr.Path)
+			assert.Equal(t, "enum", fieldErr.Key)
+			assert.Equal(t, "Invalid", fieldErr.Value)
+			assert.Equal(t, "labeler.MyEnum", fieldErr.Type)
+			assert.Equal(t, OpUnmarshal, fieldErr.Op)
+		}
+	} else {
+		assert.Fail(t, "Error should be a ParsingError")
+	}
+}
+
+type InvalidDueMultipleRequiredFields struct {
+	Enum   MyEnum            `label:"enum,required"`
+	Name   string            `label:"name,required"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestInvalidDueToMultipleRequiredFields(t *testing.T) {
+	l := StructWithLabels{
+		Labels: map[string]string{},
+	}
+
+	inv := &InvalidDueMultipleRequiredFields{}
+	err := Unmarshal(l, inv)
+	assert.Error(t, err, "Should have thrown an error")
+	assert.Error(t, err)
+
+	var parsingError *ParsingError
+	if errors.As(err, &parsingError) {
+		assert.Equal(t, 2, len(parsingError.Errors))
+		for _, fieldErr := range parsingError.Errors {
+			assert.True(t, errors.Is(fieldErr, ErrLabelRequired))
+		}
+	} else {
+		assert.Fail(t, "Error should be a ParsingError")
+	}
+
+	t.Log(err)
+}
+
+type WithAllFieldsRequired struct {
+	Name      string            `label:"name"`
+	Enum      MyEnum            `label:"enum"`
+	Optional  string            `label:"optional,notrequired"`
+	Defaulted string            `label:"defaulted,default:value"`
+	Labels    map[string]string `label:"*,required"`
+}
+
+func TestRequireAllFieldsFromContainer(t *testing.T) {
+	v := &WithAllFieldsRequired{}
+	err := Unmarshal(map[string]string{"name": "my name"}, v)
+	var parsingError *ParsingError
+	if !errors.As(err, &parsingError) {
+		assert.Fail(t, "Error should be a ParsingError")
+		return
+	}
+	if assert.Len(t, parsingError.Errors, 1) {
+		assert.Equal(t, "Enum", parsingError.Errors[0].Field)
+		assert.True(t, errors.Is(parsingError.Errors[0], ErrLabelRequired))
+	}
+	assert.Equal(t, "my name", v.Name)
+}
+
+func TestOptRequireAllFields(t *testing.T) {
+	v := &InvalidDueToMissingLabels{}
+	assert.NoError(t, Unmarshal(map[string]string{"name": "x"}, v, OptRequireAllFields()))
+
+	v2 := &TestingSlice{}
+	err := Unmarshal(map[string]string{"strings": "a"}, v2, OptRequireAllFields())
+	var parsingError *ParsingError
+	if assert.True(t, errors.As(err, &parsingError)) {
+		assert.Len(t, parsingError.Errors, 1)
+		assert.Equal(t, "Ints", parsingError.Errors[0].Field)
+	}
+}
+
+type PrefixedDatabase struct {
+	Host string `label:"host"`
+	Port int    `label:"port,default:5432"`
+	Pool struct {
+		Size int `label:"size"`
+	} `label:"pool,prefix"`
+}
+
+type WithPrefixes struct {
+	Host     string            `label:"host"`
+	Database PrefixedDatabase  `label:"db,prefix"`
+	Replica  *PrefixedDatabase `label:"replica,prefix"`
+	Labels   map[string]string `label:"*"`
+}
+
+func TestUnmarshalPrefixedStructs(t *testing.T) {
+	m := map[string]string{
+		"host":         "app",
+		"db.host":      "primary",
+		"db.pool.size": "10",
+		"replica.host": "secondary",
+		"replica.port": "6543",
+	}
+	v := WithPrefixes{}
+	err := Unmarshal(m, &v)
+	assert.NoError(t, err)
+	assert.Equal(t, "app", v.Host)
+	assert.Equal(t, "primary", v.Database.Host)
+	assert.Equal(t, 5432, v.Database.Port)
+	assert.Equal(t, 10, v.Database.Pool.Size)
+	if assert.NotNil(t, v.Replica) {
+		assert.Equal(t, "secondary", v.Replica.Host)
+		assert.Equal(t, 6543, v.Replica.Port)
+	}
+
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", res["db.host"])
+	assert.Equal(t, "5432", res["db.port"])
+	assert.Equal(t, "10", res["db.pool.size"])
+	assert.Equal(t, "secondary", res["replica.host"])
+	assert.Equal(t, "0", res["replica.pool.size"])
+}
+
+func TestPathDelimiterOption(t *testing.T) {
+	m := map[string]string{"db__host": "primary", "db__pool__size": "3", "replica__host": "secondary"}
+	v := WithPrefixes{}
+	err := Unmarshal(m, &v, OptPathDelimiter("__"))
+	assert.NoError(t, err)
+	assert.Equal(t, "primary", v.Database.Host)
+	assert.Equal(t, 3, v.Database.Pool.Size)
+	assert.Equal(t, "secondary", v.Replica.Host)
+}
+
+type WithInvalidPrefix struct {
+	Name   string            `label:"name,prefix"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestInvalidPrefix(t *testing.T) {
+	err := Unmarshal(map[string]string{}, &WithInvalidPrefix{})
+	var parsingError *ParsingError
+	if assert.True(t, errors.As(err, &parsingError)) {
+		assert.True(t, errors.Is(parsingError.Errors[0], ErrInvalidPrefix))
+	}
+
+	// a value for the prefix token namespaces keys rather than setting the
+	// path delimiter, which is set with OptPathDelimiter
+	tag, err := ParseTag("db,prefix:_")
+	assert.NoError(t, err)
+	assert.True(t, tag.Prefix)
+	assert.Equal(t, "_", tag.KeyPrefix)
+}
+
+type WithAliases struct {
+	Environment string `label:"environment,alias:env|deploy-env,legacy:env"`
+	Region      string `label:"region,alias:zone,discard"`
+	Database    struct {
+		Host string `label:"host,alias:hostname"`
+	} `label:"db,prefix"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestUnmarshalAliases(t *testing.T) {
+	matches := []AliasMatch{}
+	lbl := NewLabeler(OptAliasHandler(func(m AliasMatch) {
+		matches = append(matches, m)
+	}))
+	m := map[string]string{
+		"deploy-env":  "prod",
+		"env":         "staging",
+		"zone":        "us-east1",
+		"db.hostname": "db.example.com",
+	}
+	v := WithAliases{}
+	err := lbl.Unmarshal(m, &v)
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", v.Environment)
+	assert.Equal(t, "us-east1", v.Region)
+	assert.Equal(t, "db.example.com", v.Database.Host)
+	assert.NotContains(t, v.Labels, "zone")
+	assert.Equal(t, []AliasMatch{
+		{Field: "Environment", Key: "environment", Alias: "env"},
+		{Field: "Region", Key: "region", Alias: "zone"},
+		{Field: "Database.Host", Key: "db.host", Alias: "db.hostname"},
+	}, matches)
+
+	m["environment"] = "dev"
+	v = WithAliases{}
+	matches = matches[:0]
+	assert.NoError(t, lbl.Unmarshal(m, &v))
+	assert.Equal(t, "dev", v.Environment)
+	assert.Len(t, matches, 2)
+}
+
+func TestMarshalLegacyKey(t *testing.T) {
+	v := WithAliases{Environment: "prod", Labels: map[string]string{}}
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", res["environment"])
+	assert.Equal(t, "prod", res["env"])
+	assert.NotContains(t, res, "deploy-env")
+}
+
+func TestNamingStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		snake    string
+		kebab    string
+		screamer string
+		camel    string
+	}{
+		{"Name", "name", "name", "NAME", "name"},
+		{"HTTPServerPort", "http_server_port", "http-server-port", "HTTP_SERVER_PORT", "httpServerPort"},
+		{"UserID", "user_id", "user-id", "USER_ID", "userId"},
+		{"Retry3Times", "retry3_times", "retry3-times", "RETRY3_TIMES", "retry3Times"},
+		{"Already_Snake", "already_snake", "already-snake", "ALREADY_SNAKE", "alreadySnake"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.snake, SnakeCase(test.name))
+		assert.Equal(t, test.kebab, KebabCase(test.name))
+		assert.Equal(t, test.screamer, ScreamingSnakeCase(test.name))
+		assert.Equal(t, test.camel, LowerCamelCase(test.name))
+	}
+}
+
+type WithDerivedKeys struct {
+	ServerHost string
+	MaxConns   int
+	Timeout    time.Duration `label:"timeout_duration"`
+	Skipped    string        `label:"-"`
+	Database   struct {
+		UserName string
+	}
+	Settings struct {
+		ReadOnly bool
+	} `label:"settings,prefix"`
+	Callback func()
+	private  string
+	Labels   map[string]string `label:"*"`
+}
+
+func TestNamingStrategyOption(t *testing.T) {
+	m := map[string]string{
+		"SERVER_HOST":        "localhost",
+		"MAX_CONNS":          "12",
+		"timeout_duration":   "3s",
+		"SKIPPED":            "skipped",
+		"USER_NAME":          "admin",
+		"settings.READ_ONLY": "true",
+	}
+	v := WithDerivedKeys{}
+	err := Unmarshal(m, &v, OptNamingStrategy(ScreamingSnakeCase))
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", v.ServerHost)
+	assert.Equal(t, 12, v.MaxConns)
+	assert.Equal(t, 3*time.Second, v.Timeout)
+	assert.Equal(t, "", v.Skipped)
+	assert.Equal(t, "admin", v.Database.UserName)
+	assert.True(t, v.Settings.ReadOnly)
+
+	res, err := Marshal(&v, OptNamingStrategy(ScreamingSnakeCase))
+	assert.NoError(t, err)
+	assert.Equal(t, "12", res["MAX_CONNS"])
+	assert.Equal(t, "3s", res["timeout_duration"])
+	assert.Equal(t, "true", res["settings.READ_ONLY"])
+	assert.NotContains(t, res, "CALLBACK")
+
+	v = WithDerivedKeys{}
+	assert.NoError(t, Unmarshal(m, &v))
+	assert.Equal(t, "", v.ServerHost)
+}
+
+type WithConstraints struct {
+	Port    int               `label:"port,min:1,max:65535"`
+	Name    string            `label:"name,len:3"`
+	Env     string            `label:"env,oneof:dev|staging|prod"`
+	Version string            `label:"version,pattern:^v[0-9]+$"`
+	Owner   string            `label:"owner,nonzero"`
+	Timeout time.Duration     `label:"timeout,max:1m"`
+	Tags    []string          `label:"tags,split:;,min:1,max:3,oneof:a|b|c|d"`
+	Labels  map[string]string `label:"*"`
+}
+
+func TestUnmarshalConstraints(t *testing.T) {
+	valid := map[string]string{
+		"port":    "8080",
+		"name":    "api",
+		"env":     "prod",
+		"version": "v2",
+		"owner":   "ops",
+		"timeout": "30s",
+		"tags":    "a;b",
+	}
+	v := WithConstraints{}
+	assert.NoError(t, Unmarshal(valid, &v))
+	assert.Equal(t, []string{"a", "b"}, v.Tags)
+
+	tests := []struct {
+		key        string
+		value      string
+		constraint string
+		param      string
+	}{
+		{"port", "0", "min", "1"},
+		{"port", "70000", "max", "65535"},
+		{"name", "apis", "len", "3"},
+		{"env", "qa", "oneof", "dev|staging|prod"},
+		{"version", "2", "pattern", "^v[0-9]+$"},
+		{"owner", "", "nonzero", ""},
+		{"timeout", "2m", "max", "1m"},
+		{"tags", "a;b;c;d", "max", "3"},
+		{"tags", "a;e", "oneof", "a|b|c|d"},
+	}
+	for _, test := range tests {
+		m := map[string]string{}
+		for k, val := range valid {
+			m[k] = val
+		}
+		m[test.key] = test.value
+		v := WithConstraints{}
+		err := Unmarshal(m, &v)
+		var pErr *ParsingError
+		if !assert.True(t, errors.As(err, &pErr), test.key) || !assert.Len(t, pErr.Errors, 1, test.key) {
+			continue
+		}
+		fErr := pErr.Errors[0]
+		assert.True(t, errors.Is(fErr, ErrConstraint))
+		var vErr *ValidationError
+		if assert.True(t, errors.As(fErr, &vErr)) {
+			assert.Equal(t, test.constraint, vErr.Constraint, test.key)
+			assert.Equal(t, test.param, vErr.Param, test.key)
+			if test.constraint == "oneof" && test.key == "tags" {
+				assert.Equal(t, "e", vErr.Value)
+			} else {
+				assert.Equal(t, test.value, vErr.Value, test.key)
+			}
+			assert.Equal(t, fErr.Field, vErr.Path)
+		}
+	}
+}
+
+func TestMarshalConstraints(t *testing.T) {
+	v := WithConstraints{
+		Port:    80,
+		Name:    "web",
+		Env:     "dev",
+		Version: "v1",
+		Owner:   "ops",
+		Tags:    []string{"c"},
+	}
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "80", res["port"])
+
+	v.Env = "local"
+	_, err = Marshal(&v)
+	var pErr *ParsingError
+	assert.True(t, errors.As(err, &pErr))
+	var vErr *ValidationError
+	assert.True(t, errors.As(pErr.Errors[0], &vErr))
+	assert.Equal(t, "oneof", vErr.Constraint)
+	assert.Equal(t, "local", vErr.Value)
+}
+
+func TestMalformedConstraints(t *testing.T) {
+	_, err := ParseTag("key,pattern:[")
+	assert.True(t, errors.Is(err, ErrMalformedTag))
+	_, err = ParseTag("key,len:-1")
+	assert.True(t, errors.Is(err, ErrMalformedTag))
+	tag, err := ParseTag("key,min:1,oneof:a|b,nonzero")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", tag.Min)
+	assert.Equal(t, []string{"a", "b"}, tag.OneOf)
+	assert.True(t, tag.NonZero)
+	// oneof is not affected by AliasSeparator
+	tag, err = ParseTag("key,alias:x;y,oneof:a|b", OptAliasSeparator(";"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x", "y"}, tag.Aliases)
+	assert.Equal(t, []string{"a", "b"}, tag.OneOf)
+
+	// bounds are parsed against the field's type along with the tag, even
+	// when there is no value to unmarshal
+	var pErr *ParsingError
+	err = Unmarshal(map[string]string{}, &struct {
+		N      int               `label:"n,min:abc"`
+		Labels map[string]string `label:"*"`
+	}{})
+	assert.True(t, errors.As(err, &pErr) && errors.Is(pErr.Errors[0], ErrMalformedTag))
+	err = Unmarshal(map[string]string{}, &struct {
+		D      time.Duration     `label:"d,max:10"`
+		Labels map[string]string `label:"*"`
+	}{})
+	assert.True(t, errors.As(err, &pErr) && errors.Is(pErr.Errors[0], ErrMalformedTag))
+	err = Unmarshal(map[string]string{}, &struct {
+		B      bool              `label:"b,min:1"`
+		Labels map[string]string `label:"*"`
+	}{})
+	assert.True(t, errors.As(err, &pErr) && errors.Is(pErr.Errors[0], ErrUnsupportedType))
+}
+
+type WithBoundedValues struct {
+	N      int               `label:"n,max:10"`
+	P      *int              `label:"p,max:10"`
+	Tags   []string          `label:"tags,max:2"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestRejectedValuesAreNotAssigned(t *testing.T) {
+	v := WithBoundedValues{N: 5, Tags: []string{"a"}}
+	err := Unmarshal(map[string]string{"n": "11", "p": "12", "tags": "a,b,c"}, &v)
+	var pErr *ParsingError
+	if assert.True(t, errors.As(err, &pErr)) && assert.Len(t, pErr.Errors, 3) {
+		assert.Equal(t, `error unmarshaling N ("n"): value "11" does not satisfy max:10`, pErr.Errors[0].Error())
+	}
+	assert.Equal(t, 5, v.N)
+	assert.Nil(t, v.P)
+	assert.Equal(t, []string{"a"}, v.Tags)
+}
+
+type WithStdlibTypes struct {
+	IP       net.IP            `label:"ip"`
+	Network  net.IPNet         `label:"network"`
+	Endpoint *url.URL          `label:"endpoint"`
+	Match    *regexp.Regexp    `label:"match"`
+	Count    *big.Int          `label:"count"`
+	Ratio    big.Float         `label:"ratio"`
+	Peers    []net.IP          `label:"peers"`
+	Mirrors  []*url.URL        `label:"mirrors,split:;"`
+	Subnets  [2]net.IPNet      `label:"subnets"`
+	Labels   map[string]string `label:"*"`
+}
+
+func TestStdlibTypes(t *testing.T) {
+	m := map[string]string{
+		"ip":       "192.168.0.1",
+		"network":  "10.0.0.0/8",
+		"endpoint": "https://example.com/path?q=1",
+		"match":    "^a+b$",
+		"count":    "123456789012345678901234567890",
+		"ratio":    "0.125",
+		"peers":    "10.0.0.1,::1",
+		"mirrors":  "https://a.example.com;https://b.example.com",
+		"subnets":  "10.1.0.0/16,fd00::/8",
+	}
+	v := WithStdlibTypes{}
+	assert.NoError(t, Unmarshal(m, &v))
+	assert.Equal(t, "192.168.0.1", v.IP.String())
+	assert.Equal(t, "10.0.0.0/8", v.Network.String())
+	assert.Equal(t, "example.com", v.Endpoint.Host)
+	assert.True(t, v.Match.MatchString("aab"))
+	assert.Equal(t, "123456789012345678901234567890", v.Count.String())
+	assert.Equal(t, "0.125", v.Ratio.Text('f', -1))
+	assert.Len(t, v.Peers, 2)
+	assert.True(t, v.Peers[1].Equal(net.IPv6loopback))
+	assert.Len(t, v.Mirrors, 2)
+	assert.Equal(t, "b.example.com", v.Mirrors[1].Host)
+	assert.Equal(t, "fd00::/8", v.Subnets[1].String())
+
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	for k, val := range m {
+		assert.Equal(t, val, res[k], k)
+	}
+
+	err = Unmarshal(map[string]string{"ip": "not-an-ip"}, &WithStdlibTypes{})
+	var pErr *ParsingError
+	assert.True(t, errors.As(err, &pErr))
+}
+
+type WithNilStdlibPointers struct {
+	IP       *net.IP           `label:"ip"`
+	Network  *net.IPNet        `label:"network"`
+	Endpoint *url.URL          `label:"endpoint"`
+	Match    *regexp.Regexp    `label:"match"`
+	Count    *big.Int          `label:"count"`
+	Ratio    *big.Float        `label:"ratio"`
+	Labels   map[string]string `label:"*"`
+}
+
+func TestMarshalNilStdlibPointers(t *testing.T) {
+	res, err := Marshal(&WithNilStdlibPointers{})
+	assert.NoError(t, err)
+	for _, k := range []string{"ip", "network", "endpoint", "match", "count", "ratio"} {
+		assert.Equal(t, "", res[k], k)
+	}
+}
+
+// Celsius stands in for a third-party type that can not be given methods.
+type Celsius struct {
+	Degrees float64
+}
+
+var celsiusType = reflect.TypeOf(Celsius{})
+
+func encodeCelsius(v reflect.Value, tag Tag, o Options) (string, error) {
+	return strconv.FormatFloat(v.Interface().(Celsius).Degrees, 'f', -1, 64) + "C", nil
+}
+
+func decodeCelsius(s string, v reflect.Value, tag Tag, o Options) error {
+	d, err := strconv.ParseFloat(strings.TrimSuffix(s, "C"), 64)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(Celsius{Degrees: d}))
+	return nil
+}
+
+type WithConverters struct {
+	Temp     Celsius           `label:"temp"`
+	Max      *Celsius          `label:"max"`
+	Readings []Celsius         `label:"readings,split:;"`
+	Duration time.Duration     `label:"duration"`
+	Labels   map[string]string `label:"*"`
+}
+
+func TestConverter(t *testing.T) {
+	l := NewLabeler(
+		OptConverter(celsiusType, encodeCelsius, decodeCelsius),
+		// converters take priority over built-in types
+		OptConverter(reflect.TypeOf(time.Duration(0)),
+			func(v reflect.Value, tag Tag, o Options) (string, error) {
+				return strconv.FormatInt(int64(v.Interface().(time.Duration)/time.Second), 10), nil
+			},
+			func(s string, v reflect.Value, tag Tag, o Options) error {
+				n, err := strconv.ParseInt(s, 10, 64)
+				v.SetInt(n * int64(time.Second))
+				return err
+			}),
+	)
+	m := map[string]string{
+		"temp":     "21.5C",
+		"max":      "30C",
+		"readings": "1C;2.5C",
+		"duration": "90",
+	}
+	v := WithConverters{}
+	assert.NoError(t, l.Unmarshal(m, &v))
+	assert.Equal(t, 21.5, v.Temp.Degrees)
+	assert.Equal(t, 30.0, v.Max.Degrees)
+	assert.Equal(t, []Celsius{{1}, {2.5}}, v.Readings)
+	assert.Equal(t, 90*time.Second, v.Duration)
+
+	res, err := l.Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, m, res)
+
+	err = l.Unmarshal(map[string]string{"temp": "warm"}, &v)
+	var pErr *ParsingError
+	assert.True(t, errors.As(err, &pErr))
+
+	// converters are registered per Labeler
+	err = Unmarshal(map[string]string{"temp": "21.5C"}, &WithConverters{})
+	assert.Error(t, err)
+}
+
+func TestPointerConverter(t *testing.T) {
+	l := NewLabeler(OptConverter(reflect.PtrTo(celsiusType),
+		func(v reflect.Value, tag Tag, o Options) (string, error) {
+			return encodeCelsius(v.Elem(), tag, o)
+		},
+		func(s string, v reflect.Value, tag Tag, o Options) error {
+			return decodeCelsius(s, v.Elem(), tag, o)
+		}))
+	m := map[string]string{
+		"temp":     "21.5C",
+		"max":      "30C",
+		"readings": "1C;2.5C",
+		"duration": "1m30s",
+	}
+	v := WithConverters{}
+	assert.NoError(t, l.Unmarshal(m, &v))
+	assert.Equal(t, 21.5, v.Temp.Degrees)
+	assert.Equal(t, 30.0, v.Max.Degrees)
+	assert.Equal(t, []Celsius{{1}, {2.5}}, v.Readings)
+
+	res, err := l.Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, m, res)
+}
+
+type WithCustomTokens struct {
+	Name     string            `label:"name,mask"`
+	ID       string            `label:"id,checksum:sum"`
+	Untagged string            `label:"untagged"`
+	Labels   map[string]string `label:"*"`
+}
+
+func checksum(s string) string {
+	n := 0
+	for _, r := range s {
+		n += int(r)
+	}
+	return strconv.Itoa(n % 100)
+}
+
+func TestCustomTokens(t *testing.T) {
+	var parsed []string
+	l := NewLabeler(
+		OptToken("mask", nil, TokenHook{
+			Marshal: func(s string, ext interface{}, tag Tag, o Options) (string, error) {
+				return strings.Repeat("*", len(s)), nil
+			},
+		}),
+		OptToken("checksum", func(value string, tag *Tag, o Options) (interface{}, error) {
+			if value != "sum" {
+				return nil, ErrMalformedTag
+			}
+			parsed = append(parsed, tag.Key)
+			return checksum, nil
+		}, TokenHook{
+			Unmarshal: func(s string, ext interface{}, tag Tag, o Options) (string, error) {
+				i := strings.LastIndex(s, "-")
+				if i < 0 || ext.(func(string) string)(s[:i]) != s[i+1:] {
+					return s, errors.New("invalid checksum")
+				}
+				return s[:i], nil
+			},
+			Marshal: func(s string, ext interface{}, tag Tag, o Options) (string, error) {
+				return s + "-" + ext.(func(string) string)(s), nil
+			},
+		}),
+	)
+	assert.NoError(t, l.ValidateOptions())
+	v := WithCustomTokens{}
+	err := l.Unmarshal(map[string]string{
+		"name":     "secret",
+		"id":       "abc-94",
+		"untagged": "value",
+	}, &v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id"}, parsed)
+	assert.Equal(t, "secret", v.Name)
+	assert.Equal(t, "abc", v.ID)
+
+	res, err := l.Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "******", res["name"])
+	assert.Equal(t, "abc-94", res["id"])
+	assert.Equal(t, "value", res["untagged"])
+
+	err = l.Unmarshal(map[string]string{"id": "abc-1"}, &WithCustomTokens{})
+	var pErr *ParsingError
+	assert.True(t, errors.As(err, &pErr))
+
+	tag, err := ParseTag("id,checksum:sum,mask", OptToken("checksum", nil, TokenHook{}), OptToken("mask", nil, TokenHook{}))
+	assert.NoError(t, err)
+	ext, ok := tag.GetExtension("checksum")
+	assert.True(t, ok)
+	assert.Equal(t, "sum", ext)
+
+	// unregistered tokens remain malformed
+	_, err = ParseTag("id,checksum:sum")
+	assert.True(t, errors.Is(err, ErrMalformedTag))
+
+	l = NewLabeler(OptToken("default", nil, TokenHook{}))
+	assert.True(t, errors.Is(l.ValidateOptions(), ErrInvalidOption))
+
+	// conflicts with built-in tokens are returned rather than the custom
+	// token being ignored
+	minToken := OptToken("min", nil, TokenHook{})
+	l = NewLabeler(minToken)
+	err = l.Unmarshal(map[string]string{"name": "x"}, &WithCustomTokens{})
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+	_, err = l.Marshal(&WithCustomTokens{})
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+	err = Unmarshal(map[string]string{"name": "x"}, &WithCustomTokens{}, minToken)
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+	_, err = Marshal(&WithCustomTokens{}, minToken)
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+	_, err = Describe(&WithCustomTokens{}, minToken)
+	assert.True(t, errors.Is(err, ErrInvalidOption))
+	assert.NoError(t, Unmarshal(map[string]string{}, &WithCustomMinToken{}, minToken, OptMinToken("minimum")))
+}
+
+type WithCustomMinToken struct {
+	N      int               `label:"n,min:5"`
+	Labels map[string]string `label:"*"`
+}
+
+type PrefixMapDatabase struct {
+	Host    string        `label:"host"`
+	Port    int           `label:"port,default:5432"`
+	Timeout time.Duration `label:"timeout"`
+}
+
+type WithPrefixMaps struct {
+	Limits    map[string]int                `label:"limit.*"`
+	Timeouts  map[string]time.Duration      `label:"timeout_*"`
+	Databases map[string]PrefixMapDatabase  `label:"db.*"`
+	Replicas  map[string]*PrefixMapDatabase `label:"replica.*,discard"`
+	Labels    map[string]string             `label:"*"`
+}
+
+func TestPrefixMaps(t *testing.T) {
+	m := map[string]string{
+		"limit.cpu":            "2",
+		"limit.memory":         "512",
+		"timeout_read":         "5s",
+		"db.primary.host":      "primary.example.com",
+		"db.primary.port":      "6543",
+		"db.secondary.host":    "secondary.example.com",
+		"replica.east.host":    "east.example.com",
+		"replica.east.timeout": "1s",
+		"other":                "value",
+	}
+	v := WithPrefixMaps{}
+	assert.NoError(t, Unmarshal(m, &v))
+	assert.Equal(t, map[string]int{"cpu": 2, "memory": 512}, v.Limits)
+	assert.Equal(t, map[string]time.Duration{"read": 5 * time.Second}, v.Timeouts)
+	assert.Equal(t, map[string]PrefixMapDatabase{
+		"primary":   {Host: "primary.example.com", Port: 6543},
+		"secondary": {Host: "secondary.example.com", Port: 5432},
+	}, v.Databases)
+	assert.Equal(t, "east.example.com", v.Replicas["east"].Host)
+	assert.Equal(t, time.Second, v.Replicas["east"].Timeout)
+	assert.Equal(t, "value", v.Labels["other"])
+	assert.Equal(t, "2", v.Labels["limit.cpu"])
+	assert.NotContains(t, v.Labels, "replica.east.host")
+
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "512", res["limit.memory"])
+	assert.Equal(t, "5s", res["timeout_read"])
+	assert.Equal(t, "6543", res["db.primary.port"])
+	assert.Equal(t, "5432", res["db.secondary.port"])
+	assert.Equal(t, "east.example.com", res["replica.east.host"])
+	assert.Equal(t, "1s", res["replica.east.timeout"])
+
+	err = Unmarshal(map[string]string{"limit.cpu": "two"}, &WithPrefixMaps{})
+	var pErr *ParsingError
+	assert.True(t, errors.As(err, &pErr))
+}
+
+func TestPrefixMapUnsupported(t *testing.T) {
+	v := struct {
+		Limits map[int]int       `label:"limit.*"`
+		Labels map[string]string `label:"*"`
+	}{}
+	err := Unmarshal(map[string]string{}, &v)
+	var pErr *ParsingError
+	if assert.True(t, errors.As(err, &pErr)) {
+		assert.True(t, errors.Is(pErr.Errors[0], ErrUnsupportedType))
+	}
+}
+
+type IndexedServer struct {
+	Host string `label:"host,required"`
+	Port int    `label:"port,default:80"`
+}
+
+type WithIndexedStructs struct {
+	Servers []IndexedServer   `label:"servers"`
+	Backups []*IndexedServer  `label:"backups"`
+	Pair    [2]IndexedServer  `label:"pair"`
+	Labels  map[string]string `label:"*"`
+}
+
+func TestIndexedStructs(t *testing.T) {
+	m := map[string]string{
+		"servers.0.host": "a.example.com",
+		"servers.0.port": "8080",
+		"servers.1.host": "b.example.com",
+		"backups.0.host": "backup.example.com",
+		"pair.1.host":    "second.example.com",
+		"pair.0.host":    "first.example.com",
+	}
+	v := WithIndexedStructs{}
+	assert.NoError(t, Unmarshal(m, &v))
+	assert.Equal(t, []IndexedServer{{"a.example.com", 8080}, {"b.example.com", 80}}, v.Servers)
+	assert.Len(t, v.Backups, 1)
+	assert.Equal(t, "backup.example.com", v.Backups[0].Host)
+	assert.Equal(t, "second.example.com", v.Pair[1].Host)
+
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "8080", res["servers.0.port"])
+	assert.Equal(t, "80", res["servers.1.port"])
+	assert.Equal(t, "b.example.com", res["servers.1.host"])
+	assert.Equal(t, "backup.example.com", res["backups.0.host"])
+	assert.Equal(t, "first.example.com", res["pair.0.host"])
+
+	tests := []struct {
+		key string
+		err error
+	}{
+		{"servers.2.host", ErrIndexGap},
+		{"servers.01.host", ErrInvalidIndex},
+		{"pair.2.host", ErrIndexOutOfRange},
+	}
+	for _, test := range tests {
+		in := map[string]string{"servers.0.host": "a", test.key: "x"}
+		err := Unmarshal(in, &WithIndexedStructs{})
+		var pErr *ParsingError
+		if !assert.True(t, errors.As(err, &pErr), test.key) {
+			continue
+		}
+		var iErr *IndexError
+		assert.True(t, errors.As(pErr.Errors[0], &iErr), test.key)
+		assert.True(t, errors.Is(pErr.Errors[0], test.err), test.key)
+	}
+
+	// required fields of elements are checked
+	err = Unmarshal(map[string]string{"servers.0.port": "1"}, &WithIndexedStructs{})
+	var pErr *ParsingError
+	assert.True(t, errors.As(err, &pErr))
+}
+
+func TestIndexedStructsMerge(t *testing.T) {
+	existing := func() WithIndexedStructs {
+		return WithIndexedStructs{
+			Servers: []IndexedServer{{"a", 1}, {"b", 2}, {"c", 3}},
+			Backups: []*IndexedServer{{"backup", 4}},
+		}
+	}
+
+	// elements are merged by index rather than appended
+	v := existing()
+	backup := v.Backups[0]
+	m := map[string]string{"servers.0.host": "x", "servers.0.port": "10", "backups.0.host": "y"}
+	assert.NoError(t, Unmarshal(m, &v))
+	assert.Equal(t, []IndexedServer{{"x", 10}, {"b", 2}, {"c", 3}}, v.Servers)
+	assert.Equal(t, "y", v.Backups[0].Host)
+	assert.Equal(t, "backup", backup.Host)
+
+	v = existing()
+	assert.NoError(t, Unmarshal(m, &v, OptReplace()))
+	assert.Equal(t, []IndexedServer{{"x", 10}}, v.Servers)
+
+	// the field is left untouched if an element fails
+	m = map[string]string{"servers.0.host": "x", "servers.1.port": "20"}
+	for _, opt := range []Option{OptMerge(), OptReplace()} {
+		v = existing()
+		assert.Error(t, Unmarshal(m, &v, opt))
+		assert.Equal(t, existing().Servers, v.Servers)
+	}
+}
+
+func TestStructElementsUseCachedPlans(t *testing.T) {
+	lbl := NewLabeler()
+	m := map[string]string{
+		"servers.0.host":  "a.example.com",
+		"servers.1.host":  "b.example.com",
+		"db.primary.host": "primary.example.com",
+	}
+	indexed := WithIndexedStructs{}
+	assert.NoError(t, lbl.Unmarshal(m, &indexed))
+	prefixed := WithPrefixMaps{}
+	assert.NoError(t, lbl.Unmarshal(m, &prefixed))
+	for _, v := range []interface{}{&IndexedServer{}, &PrefixMapDatabase{}} {
+		_, ok := lbl.plans.plans.Load(reflect.TypeOf(v))
+		assert.True(t, ok, "%T should have a cached plan", v)
+	}
+
+	res, err := lbl.Marshal(&indexed)
+	assert.NoError(t, err)
+	assert.Equal(t, "b.example.com", res["servers.1.host"])
+	res, err = lbl.Marshal(&prefixed)
+	assert.NoError(t, err)
+	assert.Equal(t, "primary.example.com", res["db.primary.host"])
+}
+
+func TestIndexFormat(t *testing.T) {
+	l := NewLabeler(OptIndexFormat("[%d]"))
+	v := WithIndexedStructs{}
+	assert.NoError(t, l.Unmarshal(map[string]string{"servers[0].host": "a", "servers[1].host": "b"}, &v))
+	assert.Len(t, v.Servers, 2)
+	res, err := l.Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", res["servers[1].host"])
+
+	assert.True(t, errors.Is(NewLabeler(OptIndexFormat("[]")).ValidateOptions(), ErrInvalidOption))
+}
+
+type WithMultiValues struct {
+	Accept  []string    `label:"Accept"`
+	Agent   string      `label:"User-Agent"`
+	Retries []int       `label:"retries"`
+	Tags    []string    `label:"tags"`
+	Headers http.Header `label:"*"`
+}
+
+func TestMultiValuedInput(t *testing.T) {
+	h := http.Header{}
+	h.Add("Accept", "text/html")
+	h.Add("Accept", "application/json")
+	h.Add("User-Agent", "first")
+	h.Add("User-Agent", "last")
+	h.Add("Retries", "1")
+	h.Add("Retries", "2")
+	h.Add("Tags", "a,b")
+	h.Add("Other", "x")
+	h.Add("Other", "y")
+
+	v := WithMultiValues{}
+	assert.NoError(t, Unmarshal(h, &v))
+	assert.Equal(t, []string{"text/html", "application/json"}, v.Accept)
+	assert.Equal(t, "first", v.Agent)
+	assert.Equal(t, []int{1, 2}, v.Retries)
+	assert.Equal(t, []string{"a", "b"}, v.Tags)
+	assert.Equal(t, []string{"x", "y"}, v.Headers["Other"])
+
+	v = WithMultiValues{}
+	assert.NoError(t, Unmarshal(url.Values(h), &v, OptUseLastValue()))
+	assert.Equal(t, "last", v.Agent)
+
+	res, err := MarshalValues(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"text/html", "application/json"}, res["Accept"])
+	assert.Equal(t, []string{"1", "2"}, res["retries"])
+	assert.Equal(t, []string{"last"}, res["User-Agent"])
+	assert.Equal(t, []string{"x", "y"}, res["Other"])
+
+	single, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, "text/html,application/json", single["Accept"])
+	assert.Equal(t, "x", single["Other"])
+}
+
+type WithUnknownKeys struct {
+	Timeout time.Duration     `label:"timeout"`
+	Name    string            `label:"name"`
+	Servers []Server          `label:"servers"`
+	Labels  map[string]string `label:"*"`
+}
+
+type Server struct {
+	Host string `label:"host"`
+}
+
+func TestUnknownKeys(t *testing.T) {
+	labels := map[string]string{
+		"timout":         "5s",
+		"name":           "api",
+		"servers.0.host": "a",
+		"servers.0.hots": "b",
+		"zzz":            "x",
+	}
+	v := WithUnknownKeys{}
+	assert.NoError(t, Unmarshal(labels, &v))
+	assert.Equal(t, "5s", v.Labels["timout"])
+
+	v = WithUnknownKeys{}
+	err := Unmarshal(labels, &v, OptDisallowUnknownKeys())
+	assert.True(t, errors.Is(err, ErrUnknownKey))
+	var ukErr *UnknownKeysError
+	if assert.True(t, errors.As(err, &ukErr)) {
+		assert.Equal(t, []UnknownKey{
+			{Key: "servers.0.hots"},
+			{Key: "timout", Suggestion: "timeout"},
+			{Key: "zzz"},
+		}, ukErr.Keys)
+	}
+	assert.Contains(t, err.Error(), `"timout" (did you mean "timeout"?)`)
+
+	reported := []UnknownKey{}
+	v = WithUnknownKeys{}
+	err = Unmarshal(labels, &v, OptDiscardLabels(), OptUnknownKeyHandler(func(k UnknownKey) {
+		reported = append(reported, k)
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, reported, 3)
+	assert.Equal(t, "a", v.Servers[0].Host)
+
+	v = WithUnknownKeys{}
+	err = Unmarshal(map[string]string{"Name": "api", "servers.0.host": "a"}, &v, OptDisallowUnknownKeys())
+	assert.NoError(t, err)
+}
+
+type TransactionalNested struct {
+	Host string `label:"host"`
+}
+
+type WithTransaction struct {
+	Name    string               `label:"name"`
+	Tags    []string             `label:"tags"`
+	Nested  *TransactionalNested `label:"nested,prefix"`
+	Total   *big.Int             `label:"total"`
+	Servers []Server             `label:"servers"`
+	Port    int                  `label:"port"`
+	Labels  map[string]string    `label:"*"`
+}
+
+func TestTransactionalUnmarshal(t *testing.T) {
+	newValue := func() WithTransaction {
+		return WithTransaction{
+			Name:    "before",
+			Tags:    []string{"a"},
+			Nested:  &TransactionalNested{Host: "before"},
+			Total:   big.NewInt(1234567890),
+			Servers: []Server{{Host: "before"}},
+			Port:    80,
+			Labels:  map[string]string{"existing": "before"},
+		}
+	}
+	labels := map[string]string{
+		"name":           "after",
+		"tags":           "b,c",
+		"nested.host":    "after",
+		"total":          "42",
+		"servers.0.host": "after",
+		"port":           "not a number",
+		"other":          "after",
+	}
+
+	v := newValue()
+	assert.Error(t, Unmarshal(labels, &v, OptTransactional()))
+	assert.Equal(t, newValue(), v)
+
+	v = newValue()
+	assert.Error(t, Unmarshal(labels, &v))
+	assert.Equal(t, "after", v.Name)
+
+	labels["port"] = "8080"
+	v = newValue()
+	assert.NoError(t, Unmarshal(labels, &v, OptTransactional()))
+	assert.Equal(t, "after", v.Name)
+	assert.Equal(t, []string{"a", "b", "c"}, v.Tags)
+	assert.Equal(t, "after", v.Nested.Host)
+	assert.Equal(t, "42", v.Total.String())
+	assert.Equal(t, "after", v.Servers[0].Host)
+	assert.Equal(t, 8080, v.Port)
+	assert.Equal(t, "after", v.Labels["other"])
+}
+
+type WithMergeModes struct {
+	Tags    []string          `label:"tags"`
+	Fixed   []string          `label:"fixed,replace"`
+	Pair    [2]string         `label:"pair"`
+	Servers []Server          `label:"servers"`
+	Limits  map[string]int    `label:"limit.*"`
+	Name    string            `label:"name"`
+	Region  string            `label:"region,default:us-east1"`
+	Labels  map[string]string `label:"*"`
+}
+
+func TestMergeModes(t *testing.T) {
+	existing := func() WithMergeModes {
+		return WithMergeModes{
+			Tags:    []string{"a"},
+			Fixed:   []string{"a"},
+			Pair:    [2]string{"x", "y"},
+			Servers: []Server{{Host: "a"}, {Host: "b"}},
+			Limits:  map[string]int{"cpu": 1},
+			Name:    "existing",
+			Region:  "eu-west1",
+			Labels:  map[string]string{"old": "x"},
+		}
+	}
+	labels := map[string]string{
+		"tags":           "b",
+		"fixed":          "b",
+		"pair":           "z",
+		"servers.0.host": "c",
+		"limit.mem":      "2",
+		"other":          "y",
+	}
+
+	v := existing()
+	assert.NoError(t, Unmarshal(labels, &v))
+	assert.Equal(t, []string{"a", "b"}, v.Tags)
+	assert.Equal(t, []string{"b"}, v.Fixed)
+	assert.Equal(t, [2]string{"z", "y"}, v.Pair)
+	assert.Equal(t, []Server{{Host: "c"}, {Host: "b"}}, v.Servers)
+	assert.Equal(t, map[string]int{"cpu": 1, "mem": 2}, v.Limits)
+	assert.NotContains(t, v.Labels, "old")
+	assert.Equal(t, "existing", v.Name)
+
+	v = existing()
+	assert.NoError(t, Unmarshal(labels, &v, OptMerge()))
+	assert.Equal(t, []string{"b"}, v.Fixed)
+	assert.Equal(t, "x", v.Labels["old"])
+	assert.Equal(t, "y", v.Labels["other"])
+
+	v = existing()
+	assert.NoError(t, Unmarshal(labels, &v, OptReplace()))
+	assert.Equal(t, []string{"b"}, v.Tags)
+	assert.Equal(t, [2]string{"z", ""}, v.Pair)
+	assert.Equal(t, []Server{{Host: "c"}}, v.Servers)
+	assert.Equal(t, map[string]int{"mem": 2}, v.Limits)
+	assert.Equal(t, "existing", v.Name)
+
+	type WithMergeTag struct {
+		Tags   []string          `label:"tags,merge"`
+		Labels map[string]string `label:"*,replace"`
+	}
+	mv := WithMergeTag{Tags: []string{"a"}}
+	assert.NoError(t, Unmarshal(labels, &mv))
+	assert.Equal(t, []string{"a", "b"}, mv.Tags)
+
+	v = existing()
+	assert.NoError(t, Unmarshal(map[string]string{"tags": "b"}, &v, OptResetMissing()))
+	assert.Equal(t, []string{"a", "b"}, v.Tags)
+	assert.Nil(t, v.Fixed)
+	assert.Equal(t, [2]string{}, v.Pair)
+	assert.Nil(t, v.Servers)
+	assert.Nil(t, v.Limits)
+	assert.Equal(t, "", v.Name)
+	assert.Equal(t, "us-east1", v.Region)
+
+	_, err := ParseTag("tags,merge,replace")
+	assert.True(t, errors.Is(err, ErrMalformedTag))
+}
+
+type RichErrorDatabase struct {
+	Port int `label:"port"`
+}
+
+type WithRichErrors struct {
+	Name     string            `label:"name"`
+	Database RichErrorDatabase `label:"db,prefix"`
+	Servers  []RichErrorServer `label:"servers"`
+	Labels   map[string]string `label:"*"`
+}
+
+type RichErrorServer struct {
+	Port uint16 `label:"port"`
+}
+
+func TestRichFieldErrors(t *testing.T) {
+	v := WithRichErrors{}
+	err := Unmarshal(map[string]string{"DB.Port": "x", "servers.1.port": "y", "servers.0.port": "1"}, &v)
+	var pErr *ParsingError
+	if !assert.True(t, errors.As(err, &pErr)) || !assert.Len(t, pErr.Errors, 2) {
+		return
+	}
+	dbErr := pErr.Errors[0]
+	assert.Equal(t, "Port", dbErr.Field)
+	assert.Equal(t, "Database.Port", dbErr.Path)
+	assert.Equal(t, "DB.Port", dbErr.Key)
+	assert.Equal(t, "x", dbErr.Value)
+	assert.Equal(t, "int", dbErr.Type)
+	assert.Equal(t, OpUnmarshal, dbErr.Op)
+	assert.Equal(t, `error unmarshaling Database.Port ("DB.Port"): strconv.ParseInt: parsing "x": invalid syntax`, dbErr.Error())
+
+	srvErr := pErr.Errors[1]
+	assert.Equal(t, "Servers[1].Port", srvErr.Path)
+	assert.Equal(t, "servers.1.port", srvErr.Key)
+	assert.Equal(t, "y", srvErr.Value)
+	assert.Equal(t, "uint16", srvErr.Type)
+
+	b, err := json.Marshal(dbErr)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"field": "Port",
+		"path": "Database.Port",
+		"key": "DB.Port",
+		"value": "x",
+		"type": "int",
+		"op": "unmarshal",
+		"error": "strconv.ParseInt: parsing \"x\": invalid syntax"
+	}`, string(b))
+
+	b, err = json.Marshal(pErr)
+	assert.NoError(t, err)
+	res := struct {
+		Error  string
+		Errors []map[string]string
+	}{}
+	assert.NoError(t, json.Unmarshal(b, &res))
+	assert.Equal(t, "2 error(s) occurred while parsing (Port, Port)", res.Error)
+	assert.Len(t, res.Errors, 2)
+
+	_, err = Marshal(&WithConstraints{Port: 0, Name: "web", Env: "dev"})
+	if assert.True(t, errors.As(err, &pErr)) {
+		assert.Equal(t, OpMarshal, pErr.Errors[0].Op)
+	}
+}
+
+var errRejectedLabels = errors.New("labels rejected")
+
+type WithStrictLabelee struct {
+	Name   string `label:"name"`
+	labels map[string]string
+}
+
+func (s *WithStrictLabelee) SetLabels(labels map[string]string) error {
+	if _, ok := labels["reject"]; ok {
+		return errRejectedLabels
+	}
+	s.labels = labels
+	return nil
+}
+
+type MultiTagged struct {
+	Name       string `label:"name" attr:"name"`
+	Color      string `attr:"color"`
+	labels     map[string]string
+	attributes map[string]string
+}
+
+func (m *MultiTagged) SetLabels(labels map[string]string, tag string) error {
+	switch tag {
+	case "label":
+		m.labels = labels
+	case "attr":
+		m.attributes = labels
+	default:
+		return fmt.Errorf("unexpected tag %q", tag)
+	}
+	return nil
+}
+
+type LabelsContainer map[string]string
+
+func (lc *LabelsContainer) SetLabels(labels map[string]string) error {
+	if _, ok := labels["reject"]; ok {
+		return errRejectedLabels
+	}
+	*lc = labels
+	return nil
+}
+
+type WithStrictContainer struct {
+	Name   string          `label:"name"`
+	Labels LabelsContainer `label:"*"`
+}
+
+func TestLabeleeErrors(t *testing.T) {
+	s := WithStrictLabelee{}
+	assert.NoError(t, Unmarshal(map[string]string{"name": "x", "other": "y"}, &s))
+	assert.Equal(t, "y", s.labels["other"])
+	err := Unmarshal(map[string]string{"reject": "y"}, &s)
+	var pErr *ParsingError
+	if assert.True(t, errors.As(err, &pErr)) && assert.Len(t, pErr.Errors, 1) {
+		assert.Equal(t, "WithStrictLabelee", pErr.Errors[0].Field)
+		assert.Equal(t, "labeler.WithStrictLabelee", pErr.Errors[0].Type)
+		assert.Equal(t, OpUnmarshal, pErr.Errors[0].Op)
+		assert.True(t, errors.Is(pErr.Errors[0], errRejectedLabels))
+	}
+
+	m := MultiTagged{}
+	assert.NoError(t, Unmarshal(map[string]string{"name": "x", "other": "y"}, &m))
+	assert.Equal(t, "y", m.labels["other"])
+	assert.NoError(t, Unmarshal(map[string]string{"color": "blue", "other": "z"}, &m, OptTag("attr")))
+	assert.Equal(t, "blue", m.Color)
+	assert.Equal(t, "z", m.attributes["other"])
+	assert.NoError(t, Unmarshal(map[string]string{"x": "y"}, &m, OptTag("label")))
+
+	c := WithStrictContainer{}
+	assert.NoError(t, Unmarshal(map[string]string{"name": "x", "other": "y"}, &c))
+	assert.Equal(t, "y", c.Labels["other"])
+	err = Unmarshal(map[string]string{"reject": "y"}, &c)
+	if assert.True(t, errors.As(err, &pErr)) && assert.Len(t, pErr.Errors, 1) {
+		assert.Equal(t, "Labels", pErr.Errors[0].Path)
+		assert.Equal(t, OpUnmarshal, pErr.Errors[0].Op)
+		assert.True(t, errors.Is(pErr.Errors[0], errRejectedLabels))
+	}
+}
+
+type DescribedDatabase struct {
+	Host string `label:"host,required"`
+	Port uint16 `label:"port,default:5432,base:16"`
+}
+
+type Described struct {
+	Name     string                       `label:"name,alias:title,legacy:app_name"`
+	Ratio    float64                      `label:"ratio,floatformat:e"`
+	Started  time.Time                    `label:"started,timeformat:2006-01-02"`
+	Tags     []string                     `label:"tags,split:;"`
+	Servers  []IndexedServer              `label:"servers"`
+	Replicas map[string]DescribedDatabase `label:"replica.*"`
+	Database DescribedDatabase            `label:"db,prefix"`
+	Labels   map[string]string            `label:"*"`
+}
+
+func TestDescribe(t *testing.T) {
+	s, err := Describe((*Described)(nil))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "labeler.Described", s.Type)
+	assert.Equal(t, Leftover{Path: "Labels", Type: "map[string]string"}, s.Leftover)
+
+	name, ok := s.Key("name")
+	if assert.True(t, ok) {
+		assert.Equal(t, "Name", name.Path)
+		assert.Equal(t, "string", name.Type)
+		assert.Equal(t, []string{"title"}, name.Aliases)
+		assert.Equal(t, "app_name", name.Legacy)
+		assert.True(t, name.Keep)
+		assert.True(t, name.IgnoreCase)
+	}
+	ratio, _ := s.Key("ratio")
+	assert.Equal(t, "e", ratio.Format)
+	started, _ := s.Key("started")
+	assert.Equal(t, "2006-01-02", started.Format)
+	tags, _ := s.Key("tags")
+	assert.Equal(t, ";", tags.Split)
+	assert.Equal(t, "[]string", tags.Type)
+
+	host, ok := s.Key("servers.*.host")
+	if assert.True(t, ok) {
+		assert.Equal(t, "Servers[*].Host", host.Path)
+		assert.True(t, host.Required)
+	}
+	port, _ := s.Key("servers.*.port")
+	assert.Equal(t, "80", port.Default)
+	assert.True(t, port.HasDefault)
+	assert.Equal(t, 10, port.Base)
+
+	rport, ok := s.Key("replica.*.port")
+	if assert.True(t, ok) {
+		assert.Equal(t, 16, rport.Base)
+		assert.Equal(t, "uint16", rport.Type)
+	}
+	dbHost, ok := s.Key("db.host")
+	if assert.True(t, ok) {
+		assert.Equal(t, "Database.Host", dbHost.Path)
+	}
+
+	l := NewLabeler(OptDiscardLabels(), OptCaseSensitive())
+	s, err = l.Describe(Described{})
+	assert.NoError(t, err)
+	name, _ = s.Key("name")
+	assert.False(t, name.Keep)
+	assert.False(t, name.IgnoreCase)
+
+	s, err = Describe(&WithStrictContainer{})
+	assert.NoError(t, err)
+	assert.Equal(t, "StrictLabelee", s.Leftover.Interface)
+
+	_, err = Describe(nil)
+	assert.True(t, errors.Is(err, ErrInvalidValue))
+}
+
+type WithEnv struct {
+	Host    string        `env:"HOST"`
+	Port    int           `env:"PORT,default:8080"`
+	Timeout time.Duration `env:"TIMEOUT"`
+	Debug   bool          `env:"DEBUG"`
+}
+
+func TestEnv(t *testing.T) {
+	environ := []string{
+		"APP_HOST=example.com",
+		"APP_TIMEOUT=5s",
+		"APP_DEBUG=true",
+		"APP_=ignored",
+		"HOST=other.example.com",
+		"=C:=C:\\",
+		"PATH=/usr/bin",
+	}
+	v := WithEnv{}
+	assert.NoError(t, UnmarshalEnv(&v, OptTag("env"), OptEnvPrefix("APP_"), OptEnviron(environ)))
+	assert.Equal(t, "example.com", v.Host)
+	assert.Equal(t, 8080, v.Port)
+	assert.Equal(t, 5*time.Second, v.Timeout)
+	assert.True(t, v.Debug)
+
+	env, err := MarshalEnv(&v, OptTag("env"), OptEnvPrefix("APP_"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"APP_DEBUG=true",
+		"APP_HOST=example.com",
+		"APP_PORT=8080",
+		"APP_TIMEOUT=5s",
+	}, env)
+
+	v = WithEnv{}
+	l := NewLabeler(OptTag("env"), OptEnviron([]string{"HOST=x=y", "PORT=1"}))
+	assert.NoError(t, l.UnmarshalEnv(&v))
+	assert.Equal(t, "x=y", v.Host)
+	assert.Equal(t, 1, v.Port)
+
+	os.Setenv("LABELER_TEST_HOST", "from.environ")
+	defer os.Unsetenv("LABELER_TEST_HOST")
+	v = WithEnv{}
+	assert.NoError(t, UnmarshalEnv(&v, OptTag("env"), OptEnvPrefix("LABELER_TEST_")))
+	assert.Equal(t, "from.environ", v.Host)
+}
+
+type WithFlags struct {
+	Host     string            `label:"host,doc:host to bind"`
+	Port     int               `label:"port,default:8080,doc:port to listen on"`
+	Debug    bool              `label:"debug"`
+	Timeout  *time.Duration    `label:"timeout,default:5s"`
+	Tags     []string          `label:"tag,default:a"`
+	Database IndexedServer     `label:"db,prefix"`
+	Servers  []IndexedServer   `label:"servers"`
+	Labels   map[string]string `label:"*"`
+}
+
+func TestBindFlags(t *testing.T) {
+	v := WithFlags{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	assert.NoError(t, BindFlags(fs, &v))
+	assert.Equal(t, 8080, v.Port)
+	if assert.NotNil(t, v.Timeout) {
+		assert.Equal(t, 5*time.Second, *v.Timeout)
+	}
+	assert.Equal(t, []string{"a"}, v.Tags)
+
+	s, err := Describe(&v)
+	assert.NoError(t, err)
+	doc, _ := s.Key("host")
+	assert.Equal(t, "host to bind", doc.Doc)
+
+	port := fs.Lookup("port")
+	if assert.NotNil(t, port) {
+		assert.Equal(t, "port to listen on", port.Usage)
+		assert.Equal(t, "8080", port.DefValue)
+	}
+	assert.NotNil(t, fs.Lookup("db.host"))
+	assert.NotNil(t, fs.Lookup("db.port"))
+	assert.Nil(t, fs.Lookup("servers"))
+
+	err = fs.Parse([]string{"-host", "example.com", "-port=9090", "-debug", "-timeout", "1m", "-tag", "b,c", "-tag", "d", "-db.host", "db.example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", v.Host)
+	assert.Equal(t, 9090, v.Port)
+	assert.True(t, v.Debug)
+	assert.Equal(t, time.Minute, *v.Timeout)
+	assert.Equal(t, []string{"b", "c", "d"}, v.Tags)
+	assert.Equal(t, "db.example.com", v.Database.Host)
+
+	assert.Error(t, fs.Parse([]string{"-port", "nope"}))
+
+	assert.True(t, errors.Is(BindFlags(fs, WithFlags{}), ErrInvalidValue))
+}
+
+func TestBindFlagsUsage(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var usage strings.Builder
+	fs.SetOutput(&usage)
+	assert.NoError(t, BindFlags(fs, &WithFlags{}))
+	fs.PrintDefaults()
+	assert.Equal(t, `  -db.host value
+    	
+  -db.port value
+    	 (default 80)
+  -debug
+    	
+  -host value
+    	host to bind
+  -port value
+    	port to listen on (default 8080)
+  -tag value
+    	 (default a)
+  -timeout value
+    	 (default 5s)
+`, usage.String())
+}
+
+type WithDotenv struct {
+	Host     string            `env:"HOST,doc:host to bind"`
+	Port     int               `env:"PORT"`
+	URL      string            `env:"URL"`
+	Greeting string            `env:"GREETING"`
+	Literal  string            `env:"LITERAL"`
+	Key      string            `env:"KEY"`
+	Path     string            `env:"PATH_DIR"`
+	Mode     string            `env:"MODE"`
+	Env      map[string]string `env:"*"`
+}
+
+func TestDotenv(t *testing.T) {
+	src := `# database settings
+export HOST=example.com
+PORT = 8080 # inline comment
+URL="http://${HOST}:${PORT}/"
+GREETING="hello\n\"world\"\t\$HOME"
+LITERAL='${HOST} \n'
+KEY="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+PATH_DIR=$HOME/bin
+MODE=${MODE:-production}
+OTHER=value
+`
+	v := WithDotenv{}
+	err := UnmarshalDotenv(strings.NewReader(src), &v, OptTag("env"), OptEnviron([]string{"HOME=/home/app"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", v.Host)
+	assert.Equal(t, 8080, v.Port)
+	assert.Equal(t, "http://example.com:8080/", v.URL)
+	assert.Equal(t, "hello\n\"world\"\t$HOME", v.Greeting)
+	assert.Equal(t, `${HOST} \n`, v.Literal)
+	assert.Equal(t, "-----BEGIN KEY-----\nabc\n-----END KEY-----", v.Key)
+	assert.Equal(t, "/home/app/bin", v.Path)
+	assert.Equal(t, "production", v.Mode)
+	assert.Equal(t, "value", v.Env["OTHER"])
+
+	var b strings.Builder
+	assert.NoError(t, MarshalDotenv(&b, &v, OptTag("env")))
+	assert.True(t, strings.HasPrefix(b.String(), "# host to bind\nHOST=example.com\nPORT=8080\n"), b.String())
+	assert.Contains(t, b.String(), "GREETING=\"hello\\n\\\"world\\\"\\t\\$HOME\"\n")
+	assert.True(t, strings.HasSuffix(b.String(), "OTHER=value\n"), b.String())
+
+	res := WithDotenv{}
+	assert.NoError(t, UnmarshalDotenv(strings.NewReader(b.String()), &res, OptTag("env"), OptEnviron([]string{})))
+	assert.Equal(t, v.Greeting, res.Greeting)
+	assert.Equal(t, v.Key, res.Key)
+	assert.Equal(t, v.Literal, res.Literal)
+
+	prefixed := WithEnv{}
+	assert.NoError(t, UnmarshalDotenv(strings.NewReader("APP_HOST=a\nHOST=b\n"), &prefixed, OptTag("env"), OptEnvPrefix("APP_")))
+	assert.Equal(t, "a", prefixed.Host)
+
+	var sErr *SyntaxError
+	err = UnmarshalDotenv(strings.NewReader("HOST=a\nPORT\n"), &WithEnv{}, OptTag("env"))
+	if assert.True(t, errors.As(err, &sErr)) {
+		assert.Equal(t, 2, sErr.Line)
+		assert.True(t, errors.Is(err, ErrSyntax))
+	}
+	err = UnmarshalDotenv(strings.NewReader("HOST=\"a\n"), &WithEnv{}, OptTag("env"))
+	assert.True(t, errors.Is(err, ErrUnterminatedQuote))
+}
+
+type FileDatabase struct {
+	Host string `label:"host,doc:database host"`
+	Port int    `label:"port"`
+}
+
+type WithFileFormats struct {
+	Name     string            `label:"app.name,doc:application name"`
+	Greeting string            `label:"greeting"`
+	Motd     string            `label:"motd"`
+	Database FileDatabase      `label:"db,prefix"`
+	Labels   map[string]string `label:"*"`
+}
+
+func TestProperties(t *testing.T) {
+	src := `# comment
+! another comment
+app.name = My App
+greeting:caf\u00e9 \uD83D\uDE00
+motd   first line \
+       continued\n\
+    second line
+db.host=db.example.com
+db.port 5432
+key\ with\ spaces=value\=with\:separators
+`
+	v := WithFileFormats{}
+	assert.NoError(t, UnmarshalProperties(strings.NewReader(src), &v))
+	assert.Equal(t, "My App", v.Name)
+	assert.Equal(t, "café 😀", v.Greeting)
+	assert.Equal(t, "first line continued\nsecond line", v.Motd)
+	assert.Equal(t, "db.example.com", v.Database.Host)
+	assert.Equal(t, 5432, v.Database.Port)
+	assert.Equal(t, "value=with:separators", v.Labels["key with spaces"])
+
+	v.Greeting = " café 😀"
+	var b strings.Builder
+	assert.NoError(t, MarshalProperties(&b, &v))
+	out := b.String()
+	assert.True(t, strings.HasPrefix(out, "# application name\napp.name=My App\ngreeting=\\ caf\\u00E9 \\uD83D\\uDE00\nmotd=first line continued\\n\\\n    second line\n"), out)
+	assert.Contains(t, out, "\n# database host\ndb.host=db.example.com\ndb.port=5432\n")
+	assert.Contains(t, out, "key\\ with\\ spaces=value\\=with\\:separators\n")
+
+	res := WithFileFormats{}
+	assert.NoError(t, UnmarshalProperties(strings.NewReader(out), &res))
+	assert.Equal(t, v.Name, res.Name)
+	assert.Equal(t, v.Greeting, res.Greeting)
+	assert.Equal(t, v.Motd, res.Motd)
+	assert.Equal(t, v.Database, res.Database)
+
+	var sErr *SyntaxError
+	err := UnmarshalProperties(strings.NewReader("a=b\nc=\\u12\n"), &WithFileFormats{})
+	if assert.True(t, errors.As(err, &sErr)) {
+		assert.Equal(t, "properties", sErr.Format)
+		assert.Equal(t, 2, sErr.Line)
+	}
+}
+
+func TestINI(t *testing.T) {
+	src := `; comment
+app.name = My App
+greeting = "  hello ; \"world\"  "
+motd: welcome # inline comment
+
+[db]
+# database settings
+host = db.example.com
+port = 5432
+
+[other]
+key = value
+`
+	v := WithFileFormats{}
+	assert.NoError(t, UnmarshalINI(strings.NewReader(src), &v))
+	assert.Equal(t, "My App", v.Name)
+	assert.Equal(t, `  hello ; "world"  `, v.Greeting)
+	assert.Equal(t, "welcome", v.Motd)
+	assert.Equal(t, "db.example.com", v.Database.Host)
+	assert.Equal(t, 5432, v.Database.Port)
+	assert.Equal(t, "value", v.Labels["other.key"])
+
+	var b strings.Builder
+	assert.NoError(t, MarshalINI(&b, &v, OptDiscardLabels()))
+	out := b.String()
+	assert.True(t, strings.HasPrefix(out, "; application name\napp.name = My App\ngreeting = \"  hello ; \\\"world\\\"  \"\nmotd = welcome\n"), out)
+	assert.Contains(t, out, "\n[db]\n; database host\nhost = db.example.com\nport = 5432\n")
+
+	res := WithFileFormats{}
+	assert.NoError(t, UnmarshalINI(strings.NewReader(out), &res))
+	assert.Equal(t, v.Name, res.Name)
+	assert.Equal(t, v.Greeting, res.Greeting)
+	assert.Equal(t, v.Database, res.Database)
+	assert.Equal(t, "value", res.Labels["other.key"])
+
+	var sErr *SyntaxError
+	err := UnmarshalINI(strings.NewReader("a = b\n[db\n"), &WithFileFormats{})
+	if assert.True(t, errors.As(err, &sErr)) {
+		assert.Equal(t, "ini", sErr.Format)
+		assert.Equal(t, 2, sErr.Line)
+		assert.True(t, errors.Is(err, ErrSyntax))
+	}
+}
+
+type WithKubernetesLabels struct {
+	App     string            `label:"app"`
+	Team    string            `label:"team,prefix:example.com/"`
+	Version string            `label:"version,prefix:example.com/"`
+	Labels  map[string]string `label:"*"`
+}
+
+func TestKubernetesLabels(t *testing.T) {
+	valid := map[string]string{
+		"app":                  "api",
+		"example.com/team":     "platform",
+		"example.com/version":  "v1.2.3",
+		"app.kubernetes.io/id": "",
+	}
+	v := WithKubernetesLabels{}
+	assert.NoError(t, Unmarshal(valid, &v, OptKubernetesLabels()))
+	assert.Equal(t, "platform", v.Team)
+	assert.Equal(t, "v1.2.3", v.Version)
+
+	res, err := Marshal(&v, OptKubernetesLabels())
+	assert.NoError(t, err)
+	assert.Equal(t, "platform", res["example.com/team"])
+
+	invalid := map[string]string{
+		"app":                 "-api",
+		"example.com/team":    strings.Repeat("x", 64),
+		"Example.com/other":   "x",
+		"a/b/c":               "x",
+		"example.com/version": "v1",
+	}
+	err = Unmarshal(invalid, &WithKubernetesLabels{}, OptKubernetesLabels())
+	var pErr *ParsingError
+	if assert.True(t, errors.As(err, &pErr)) && assert.Len(t, pErr.Errors, 4) {
+		byKey := map[string]*FieldError{}
+		for _, fe := range pErr.Errors {
+			assert.True(t, errors.Is(fe, ErrKubernetes))
+			assert.Equal(t, OpUnmarshal, fe.Op)
+			byKey[fe.Key] = fe
+		}
+		assert.Equal(t, "App", byKey["app"].Path)
+		assert.Equal(t, "-api", byKey["app"].Value)
+		assert.Equal(t, "Team", byKey["example.com/team"].Path)
+		assert.Equal(t, "Labels", byKey["Example.com/other"].Path)
+		var kErr *KubernetesError
+		if assert.True(t, errors.As(byKey["a/b/c"], &kErr)) {
+			assert.Contains(t, kErr.Reason, "name")
+		}
+	}
+
+	v = WithKubernetesLabels{App: "has space"}
+	_, err = Marshal(&v, OptKubernetesLabels())
+	if assert.True(t, errors.As(err, &pErr)) {
+		assert.Equal(t, OpMarshal, pErr.Errors[0].Op)
+		assert.Equal(t, "App", pErr.Errors[0].Path)
+	}
+
+	annotations := map[string]string{"example.com/description": "has spaces, and is long " + strings.Repeat(".", 100)}
+	assert.NoError(t, Unmarshal(annotations, &WithKubernetesLabels{}, OptKubernetesAnnotations()))
+	annotations["big"] = strings.Repeat("x", 256*1024)
+	err = Unmarshal(annotations, &WithKubernetesLabels{}, OptKubernetesAnnotations())
+	assert.True(t, errors.Is(err, ErrParsing))
+	if assert.True(t, errors.As(err, &pErr)) {
+		assert.True(t, errors.Is(pErr.Errors[0], ErrKubernetes))
+	}
+
+	err = Unmarshal(map[string]string{}, &struct {
+		Name string `label:"name,prefix"`
+	}{})
+	assert.True(t, errors.As(err, &pErr))
+}
+
+type WithNamespacedStruct struct {
+	Team struct {
+		Name string `label:"name"`
+	} `label:"team,prefix:example.com/"`
+	Labels map[string]string `label:"*"`
+}
+
+func TestPrefixNamespace(t *testing.T) {
+	m := map[string]string{
+		"example.com/team.name": "platform",
+	}
+	v := WithNamespacedStruct{}
+	assert.NoError(t, Unmarshal(m, &v, OptKubernetesLabels()))
+	assert.Equal(t, "platform", v.Team.Name)
+
+	res, err := Marshal(&v)
+	assert.NoError(t, err)
+	assert.Equal(t, m, res)
+
+	err = Unmarshal(map[string]string{}, &struct {
+		Labels map[string]string `label:"*,prefix:example.com/"`
+	}{})
+	var pErr *ParsingError
+	if assert.True(t, errors.As(err, &pErr)) {
+		assert.True(t, errors.Is(pErr.Errors[0], ErrInvalidPrefix))
+	}
+}
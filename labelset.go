@@ -0,0 +1,45 @@
+package labeler
+
+// LabelSet is a copy of a map[string]string of labels that supports case
+// insensitive lookups. It is used by code generated with labeler-gen in place
+// of reflection.
+type LabelSet struct {
+	kvs keyValues
+}
+
+// NewLabelSet returns a LabelSet containing a copy of m.
+func NewLabelSet(m map[string]string) *LabelSet {
+	ls := &LabelSet{
+		kvs: newKeyValues(),
+	}
+	ls.kvs.Add(m)
+	return ls
+}
+
+// Get returns the value of key and whether or not it was present.
+func (ls *LabelSet) Get(key string, ignoreCase bool) (string, bool) {
+	kv, ok := ls.kvs.Get(key, ignoreCase)
+	return kv.Value, ok
+}
+
+// Lookup returns the value of the first of keys that is present, its index in
+// keys, and whether or not any were present.
+func (ls *LabelSet) Lookup(keys []string, ignoreCase bool) (string, int, bool) {
+	kv, i, ok := ls.kvs.GetFirst(keys, ignoreCase)
+	return kv.Value, i, ok
+}
+
+// Set assigns value to key.
+func (ls *LabelSet) Set(key string, value string) {
+	ls.kvs.Set(key, value)
+}
+
+// Delete removes key.
+func (ls *LabelSet) Delete(key string) {
+	ls.kvs.Delete(key)
+}
+
+// Map returns the labels as a map[string]string.
+func (ls *LabelSet) Map() map[string]string {
+	return ls.kvs.Map()
+}
@@ -27,6 +27,7 @@ func getMarshal(r reflected, o Options) marshalFunc {
 }
 
 var fieldMarshalers = marshalerFuncs{
+	marshalConverter,
 	marshalArrayOrSlice,
 	marshalMarshalerWithOpts,
 	marshalMarshaler,
@@ -37,6 +38,7 @@ var fieldMarshalers = marshalerFuncs{
 }
 
 var collectionMarshalers = marshalerFuncs{
+	marshalConverter,
 	marshalFieldPkgString,
 	marshalFieldStringer,
 	marshalFieldTextMarshaler,
@@ -49,6 +51,7 @@ var containerMarshalers = marshalerFuncs{
 	marshalGenericallyLabeled,
 	marshalLabeled,
 	marshalMap,
+	marshalMultiMap,
 }
 
 var subjectMarshalers = marshalerFuncs{
@@ -62,6 +65,7 @@ var inputMarshalers = marshalerFuncs{
 	marshalGenericallyLabeled,
 	marshalLabeled,
 	marshalMap,
+	marshalMultiMap,
 }
 
 func (list marshalerFuncs) Marshaler(r reflected, o Options) marshalFunc {
@@ -120,7 +124,7 @@ var marshalFieldStringer = func(r reflected, o Options) marshalFunc {
 		return nil
 	}
 	var fstr fieldStringer = func(f *field, o Options) (string, error) {
-		u := r.Interface().(fmt.Stringer)
+		u := f.Interface().(fmt.Stringer)
 		return u.String(), nil
 	}
 	return fstr.Marshaler(r, o)
@@ -131,7 +135,7 @@ var marshalFieldTextMarshaler = func(r reflected, o Options) marshalFunc {
 		return nil
 	}
 	var fstr fieldStringer = func(f *field, o Options) (string, error) {
-		u := r.Interface().(TextMarshaler)
+		u := f.Interface().(TextMarshaler)
 		t, err := u.MarshalText()
 		return string(t), err
 	}
@@ -146,8 +150,15 @@ var marshalFieldString = func(r reflected, o Options) marshalFunc {
 	return strGetter.Marshaler(r, o)
 }
 
+// isPkgType reports whether t is handled by fieldStringerPkgs, such as net.IP,
+// rather than as a collection.
+func isPkgType(t reflect.Type) bool {
+	_, ok := fieldStringerPkgs[t.PkgPath()][t.Name()]
+	return ok
+}
+
 var marshalArrayOrSlice = func(r reflected, o Options) marshalFunc {
-	if (!r.IsArray() && !r.IsSlice()) || r.ColType().Implements(stringeeType) || r.IsElem() {
+	if (!r.IsArray() && !r.IsSlice()) || r.ColType().Implements(stringeeType) || isPkgType(r.ColType()) || r.IsElem() {
 		return nil
 	}
 	r.SetIsElem(true)
@@ -169,7 +180,9 @@ var marshalArrayOrSlice = func(r reflected, o Options) marshalFunc {
 		f := r.(*field)
 		strs := []string{}
 
-		for i := 0; i < r.Len(); i++ {
+		// the length of slices is read from the value as it may have changed
+		// since r was created, such as by the default of a flag
+		for i := 0; i < r.ColValue().Len(); i++ {
 			r.SetValue(r.ColValue().Index(i))
 			if r.deref() {
 				r.PtrValue().Set(r.Value().Addr())
@@ -185,7 +198,11 @@ var marshalArrayOrSlice = func(r reflected, o Options) marshalFunc {
 			}
 		}
 
-		kvs.Set(f.key, strings.Join(strs, f.split(o)))
+		s := strings.Join(strs, f.split(o))
+		if err := f.validateCollection(s, o); err != nil {
+			return err
+		}
+		kvs.SetValues(f.key, s, strs)
 
 		return nil
 	}
@@ -232,6 +249,29 @@ var marshalMap = func(r reflected, o Options) marshalFunc {
 	}
 }
 
+var marshalMultiMap = func(r reflected, o Options) marshalFunc {
+	if !r.Assignable(multiMapType) {
+		return nil
+	}
+	return func(r reflected, kvs *keyValues, o Options) error {
+		iter := r.Value().MapRange()
+		for iter.Next() {
+			values := []string{}
+			for _, v := range iter.Value().Interface().([]string) {
+				if o.OmitEmpty && v == "" {
+					continue
+				}
+				values = append(values, v)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			kvs.SetValues(iter.Key().String(), o.pickValue(values), values)
+		}
+		return nil
+	}
+}
+
 type fieldStringer func(f *field, o Options) (string, error)
 
 func (get fieldStringer) Marshaler(r reflected, o Options) marshalFunc {
@@ -244,10 +284,17 @@ func (get fieldStringer) Marshaler(r reflected, o Options) marshalFunc {
 		if err != nil {
 			return err
 		}
+		f.raw = s
+		if err := f.validate(s, o); err != nil {
+			return err
+		}
+		if s, err = f.marshalHooks(s, o); err != nil {
+			return err
+		}
 		if s == "" {
 			s = f.Default(o)
 		}
-		if o.OmitEmpty && s == "" {
+		if f.OmitEmpty(o) && s == "" {
 			return nil
 		}
 		kvs.Set(f.key, s)
@@ -264,6 +311,32 @@ var fieldStringerPkgs = map[string]map[string]fieldStringer{
 			return f.formatDuration(o)
 		},
 	},
+	"net": {
+		"IP": func(f *field, o Options) (string, error) {
+			return f.formatIP(o)
+		},
+		"IPNet": func(f *field, o Options) (string, error) {
+			return f.formatIPNet(o)
+		},
+	},
+	"net/url": {
+		"URL": func(f *field, o Options) (string, error) {
+			return f.formatURL(o)
+		},
+	},
+	"regexp": {
+		"Regexp": func(f *field, o Options) (string, error) {
+			return f.formatRegexp(o)
+		},
+	},
+	"math/big": {
+		"Int": func(f *field, o Options) (string, error) {
+			return f.formatBigInt(o)
+		},
+		"Float": func(f *field, o Options) (string, error) {
+			return f.formatBigFloat(o)
+		},
+	},
 }
 
 var fieldStringerBasic = map[reflect.Kind]fieldStringer{
@@ -75,6 +75,7 @@ type meta struct {
 	isPtr        bool
 	isArray      bool
 	isSlice      bool
+	isMap        bool
 	canAddr      bool
 	canSet       bool
 	canInterface bool
@@ -174,6 +175,14 @@ func (m *meta) Kind() reflect.Kind {
 }
 
 func (m *meta) checkArraySlice() bool {
+	if m.kind == reflect.Map {
+		// maps are only treated as collections by fields with a MapPrefix
+		m.isMap = true
+		m.colType = m.typ
+		m.colValue = m.value
+		m.colKind = m.kind
+		return false
+	}
 
 	if m.kind != reflect.Slice && m.kind != reflect.Array {
 		return false
@@ -194,22 +203,22 @@ func (m *meta) checkArraySlice() bool {
 }
 
 func (m *meta) PrepCollection() {
-	m.value = reflect.New(m.typ).Elem()
-	m.typ = m.typ.Elem()
+	m.typ = m.colType.Elem()
 	m.kind = m.typ.Kind()
 	m.colElemKind = m.kind
 	m.colElemType = m.typ
 	m.isElem = true
+	m.SetValue(reflect.New(m.typ).Elem())
+	// pointer elements are resolved by the type they point to
+	m.deref()
 }
 
 func (m *meta) ResetCollection() {
-	if !m.isArray && !m.isSlice {
+	if !m.isArray && !m.isSlice && !m.isMap {
 		return
 	}
-	m.typ = m.colType
-	m.kind = m.colKind
-	m.value = m.colValue
 	m.isElem = false
+	m.SetValue(m.colValue)
 }
 
 func (m *meta) deref() bool {
@@ -228,13 +237,33 @@ func (m *meta) deref() bool {
 	m.value = ptr
 	m.typ = ptr.Type()
 	m.kind = ptr.Kind()
-
+	if m.isElem {
+		m.setType()
+	}
 	return true
 
 }
 
+// SetValue sets the value of m, updating the type information to match rv.
+// It is used when iterating over the elements of a collection.
 func (m *meta) SetValue(rv reflect.Value) {
 	m.value = rv
+	m.typ = rv.Type()
+	m.kind = rv.Kind()
+	m.setType()
+}
+
+func (m *meta) setType() {
+	m.typeName = m.typ.Name()
+	m.pkgPath = m.typ.PkgPath()
+	m.canAddr = m.value.CanAddr()
+	if m.canAddr {
+		m.addr = m.value.Addr()
+		m.addrType = m.addr.Type()
+	} else {
+		m.addr = reflect.Value{}
+		m.addrType = nil
+	}
 }
 
 func (m *meta) IsStruct() bool {
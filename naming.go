@@ -0,0 +1,82 @@
+package labeler
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives a label key from a field's name. It is used to
+// assign keys to exported fields without a tag when Options.NamingStrategy is
+// set.
+type NamingStrategy func(name string) string
+
+// SnakeCase is a NamingStrategy which converts name to snake_case
+//
+//	HTTPServerPort -> http_server_port
+func SnakeCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "_"))
+}
+
+// KebabCase is a NamingStrategy which converts name to kebab-case
+//
+//	HTTPServerPort -> http-server-port
+func KebabCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// ScreamingSnakeCase is a NamingStrategy which converts name to SCREAMING_SNAKE_CASE,
+// typically used for environment variables
+//
+//	HTTPServerPort -> HTTP_SERVER_PORT
+func ScreamingSnakeCase(name string) string {
+	return strings.ToUpper(strings.Join(splitWords(name), "_"))
+}
+
+// LowerCamelCase is a NamingStrategy which converts name to lowerCamelCase
+//
+//	HTTPServerPort -> httpServerPort
+func LowerCamelCase(name string) string {
+	words := splitWords(name)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if i > 0 {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			w = string(r)
+		}
+		words[i] = w
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits name into words at changes in case, treating runs of
+// upper case letters as acronyms. Digits belong to the preceding word and
+// underscores are treated as separators.
+func splitWords(name string) []string {
+	words := []string{}
+	runes := []rune(name)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' || r == '-' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		prev := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
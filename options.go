@@ -31,16 +31,30 @@ func getDefaultOptions() Options {
 		UintBaseToken:      "uintbase",
 		IntBaseToken:       "intbase",
 		SplitToken:         "split",
+		PrefixToken:        "prefix",
+		AliasToken:         "alias",
+		LegacyToken:        "legacy",
+		MinToken:           "min",
+		MaxToken:           "max",
+		LenToken:           "len",
+		OneOfToken:         "oneof",
+		PatternToken:       "pattern",
+		NonZeroToken:       "nonzero",
+		MergeToken:         "merge",
+		ReplaceToken:       "replace",
+		DocToken:           "doc",
+		AliasSeparator:     "|",
+		PathDelimiter:      ".",
+		IndexFormat:        ".%d",
 		Separator:          ",",
 		AssignmentStr:      ":",
 		TimeFormat:         "",
 		ContainerField:     "",
 		Split:              ",",
+		RequireAllFields:   false,
+		RequiredToken:      "required",
+		NotRequiredToken:   "notrequired",
 		// CaseSensitiveTokens: true,
-		// RequireAllFields:    false,
-		// RequiredToken:       "required",
-		// NotRequiredToken:    "notrequired",
-
 	}
 
 	return o
@@ -63,6 +77,51 @@ type Options struct {
 	// 	default: ","
 	// What arrays and slices are split on
 	Split string
+
+	// 	default: "."
+	// PathDelimiter joins the key of a nested struct marked with PrefixToken to the
+	// keys of its fields.
+	// Example: Database Database `label:"db,prefix"` // db.host
+	PathDelimiter string
+
+	// 	default: ".%d"
+	// IndexFormat formats the index of each element of a slice or array of structs,
+	// which is placed between the field's key and PathDelimiter. It must contain %d.
+	// Example: Servers []Server `label:"servers"` // servers.0.host
+	// Example: with IndexFormat "[%d]"              // servers[0].host
+	IndexFormat string
+
+	// 	default: nil
+	// NamingStrategy, if set, derives keys for exported fields which are not tagged
+	// (aside from nested structs, which continue to be walked). A tag always takes
+	// precedence and `label:"-"` excludes a field.
+	// Available strategies are SnakeCase, KebabCase, ScreamingSnakeCase, and LowerCamelCase.
+	NamingStrategy NamingStrategy
+
+	// 	default: "|"
+	// AliasSeparator separates the keys provided to AliasToken
+	// Example: Env string `label:"environment,alias:env|deploy-env"`
+	AliasSeparator string
+
+	// 	default: nil
+	// AliasHandler, if set, is called whenever a field is unmarshaled from one of
+	// its aliases rather than its key. This is useful for tracking the migration of
+	// renamed keys.
+	AliasHandler func(m AliasMatch)
+
+	// 	default: false
+	// DisallowUnknownKeys causes Unmarshal to return an UnknownKeysError if the
+	// input has keys which were not consumed by a tagged field. Each unknown key
+	// is accompanied by the closest known key, if any, as a suggestion. Types
+	// implementing Unmarshaler or UnmarshalerWithOpts, other than those
+	// generated by labeler-gen, are handed every key and are not checked.
+	DisallowUnknownKeys bool
+
+	// 	default: nil
+	// UnknownKeyHandler, if set, is called by Unmarshal for each key in the input
+	// which was not consumed by a tagged field, regardless of DisallowUnknownKeys.
+	UnknownKeyHandler func(k UnknownKey)
+
 	// 	default: true
 	// Determines whether or not to case sensitivity should apply to labels.
 	// this is overridden if `label:"*,ignorecase"` or `label:"*,casesensitive"
@@ -93,15 +152,61 @@ type Options struct {
 	// Example: `label:"*, omitempty"` or `label:"*, includeempty"`
 	OmitEmpty bool
 
-	// // 	default: false
-	// // RequireAllFields Determines whether or not all fields are required
-	// // Individual fields can override this setting at the field level by appending
-	// // "required", "notrequired", or a custom configured RequiredToken or NotRequiredToken.
-	// //
-	// // Example:
-	// //	MyField string `label:"myField,required"` // required
-	// // 	MyField string `label:"myField,notrequired"` // not required
-	// RequireAllFields bool
+	// 	default: false
+	// RequireAllFields Determines whether or not all fields are required
+	// Individual fields can override this setting at the field level by appending
+	// "required", "notrequired", or a custom configured RequiredToken or NotRequiredToken.
+	// This can be set at the container level
+	//
+	// Example:
+	//	MyField string `label:"myField,required"` // required
+	// 	MyField string `label:"myField,notrequired"` // not required
+	// 	Labels map[string]string `label:"*,required"` // all fields are required
+	RequireAllFields bool
+
+	// 	default: false
+	// UseLastValue determines which value of a multi-valued label, such as those
+	// from a map[string][]string (http.Header, url.Values), is assigned to scalar
+	// fields. The first value is used unless UseLastValue is true. Slices and
+	// arrays receive every value.
+	UseLastValue bool
+
+	// 	default: false
+	// Transactional causes Unmarshal to decode into a deep copy of v, assigning
+	// the copy to v only if there are no errors. v is left untouched otherwise.
+	// Note that pointers, maps and slices held by v are replaced by their copies
+	// rather than updated in place.
+	Transactional bool
+
+	// 	default: MergeDefault
+	// MergeMode determines whether slices, arrays, prefixed maps and the container
+	// are merged with their existing values or replaced when their labels are
+	// present. Fields can override this with MergeToken or ReplaceToken, as can the
+	// container tag for the container and every field. Slices and arrays of
+	// structs, which use indexed keys, are merged by index rather than appended to.
+	MergeMode MergeMode
+
+	// 	default: false
+	// ResetMissing causes Unmarshal to set fields whose keys are not present in
+	// the labels to their zero value, or to their default if one is set.
+	ResetMissing bool
+
+	// 	default: KubernetesOff
+	// KubernetesMode validates every key and value marshaled or unmarshaled
+	// against the rules Kubernetes applies to metadata.labels or
+	// metadata.annotations. Violations are returned as FieldErrors wrapping a
+	// *KubernetesError.
+	KubernetesMode KubernetesMode
+
+	// 	default: ""
+	// EnvPrefix is prepended to each key by MarshalEnv. UnmarshalEnv only reads
+	// the variables which start with EnvPrefix, removing it from their keys.
+	EnvPrefix string
+
+	// 	default: nil
+	// Environ is the environment, in the form "KEY=value", read by UnmarshalEnv.
+	// os.Environ() is used if Environ is nil.
+	Environ []string
 
 	// 	default: ""
 	// Default sets a global default value for all fields not available in the labels.
@@ -148,13 +253,13 @@ type Options struct {
 	// given field if it is not present in the labels map.
 	DefaultToken string `option:"token"`
 
-	// // 	default: "required"
-	// // RequiredToken is the token used at the tag level to set the field as being required
-	// RequiredToken string `option:"token"`
+	// 	default: "required"
+	// RequiredToken is the token used at the tag level to set the field as being required
+	RequiredToken string `option:"token"`
 
-	// // 	default: "notrequired"
-	// // NotRequiredToken is the token used at the tag level to set the field as being not required
-	// NotRequiredToken string `option:"token"`
+	// 	default: "notrequired"
+	// NotRequiredToken is the token used at the tag level to set the field as being not required
+	NotRequiredToken string `option:"token"`
 
 	// 	default: "keep"
 	// KeepToken is the token used at the tag level to indicate that the field should be carried over
@@ -229,16 +334,94 @@ type Options struct {
 	IntBaseToken string `option:"token"`
 	SplitToken   string `option:"token"`
 
+	// 	default: "prefix"
+	// PrefixToken marks a nested struct field as a namespace for its fields, using the
+	// field's key as a prefix joined by PathDelimiter. A value, such as
+	// "example.com/" in `label:"team,prefix:example.com/"`, is prepended to the
+	// field's keys; on nested structs, it precedes the prefix of their keys.
+	PrefixToken string `option:"token"`
+
+	// 	default: "alias"
+	// AliasToken sets alternate keys for a field, separated by AliasSeparator. When
+	// unmarshaling, the key is tried first, followed by each alias in order.
+	AliasToken string `option:"token"`
+
+	// 	default: "legacy"
+	// LegacyToken sets an additional key, typically one of the field's aliases, that
+	// the field's value is written to when marshaling.
+	LegacyToken string `option:"token"`
+
+	// 	default: "min"
+	// MinToken sets the lower bound of a field's value. Numbers are compared by
+	// value (durations for time.Duration) while strings, slices, arrays and maps
+	// are compared by length.
+	MinToken string `option:"token"`
+
+	// 	default: "max"
+	// MaxToken sets the upper bound of a field's value, compared as with MinToken.
+	MaxToken string `option:"token"`
+
+	// 	default: "len"
+	// LenToken sets the exact length of a string, slice, array or map field.
+	LenToken string `option:"token"`
+
+	// 	default: "oneof"
+	// OneOfToken sets the values, separated by "|", that a field's label
+	// value must be one of. For collections, each element is checked.
+	OneOfToken string `option:"token"`
+
+	// 	default: "pattern"
+	// PatternToken sets a regular expression that a field's label value must match.
+	// For collections, each element is checked. The expression may not contain
+	// Separator.
+	PatternToken string `option:"token"`
+
+	// 	default: "nonzero"
+	// NonZeroToken requires a field's value to not be the zero value.
+	NonZeroToken string `option:"token"`
+
+	// 	default: "merge"
+	// MergeToken marks a slice, array, prefixed map or container field as being
+	// merged with its existing value when unmarshaling. See MergeMode.
+	MergeToken string `option:"token"`
+
+	// 	default: "replace"
+	// ReplaceToken marks a slice, array, prefixed map or container field as being
+	// replaced outright when unmarshaling. See MergeMode.
+	ReplaceToken string `option:"token"`
+
+	// 	default: "doc"
+	// DocToken sets the description of a field, such as the usage text of the
+	// flag registered by BindFlags.
+	DocToken string `option:"token"`
+
 	tokenParsers tagTokenParsers
 
+	converters converters
+
+	customTokens customTokens
+
+	// customTokenErr is the error, if any, from validateCustomTokens. It is
+	// returned whenever a subject is created with these Options.
+	customTokenErr error
+
 	unmarshaling bool
+
+	// containerOptional allows subjects without a container, discarding
+	// leftover labels, for sources such as the environment where most of the
+	// input is unrelated to v.
+	containerOptional bool
+
+	// plans is the planCache of the Labeler in use, if any, through which the
+	// struct elements of prefixed maps and indexed collections are bound.
+	plans *planCache
 }
 
 // FromTag sets options from t if t is on a container field (either marked as a container with a tag set
 // to Options.ContainerFlag) or Options.ContainerField
 // Options that can be updated from the tag are:
 // FloatFormat, TimeFormat (via TimeFormatToken), KeepLabels (via Options.KeepToken / Options.DiscardToken),
-// IgnoreCase (via Options.IgnoreCaseToken)
+// IgnoreCase (via Options.IgnoreCaseToken), RequireAllFields (via Options.RequiredToken / Options.NotRequiredToken)
 // returns: true if successful, false otherwise
 func (o Options) FromTag(t *Tag) Options {
 	if t == nil {
@@ -271,12 +454,65 @@ func (o Options) FromTag(t *Tag) Options {
 	if t.IncludeEmptyIsSet {
 		o.OmitEmpty = false
 	}
-	// if t.RequiredIsSet {
-	// 	o.RequireAllFields = t.Required
-	// }
+	if t.RequiredIsSet {
+		o.RequireAllFields = t.Required
+	}
+	if t.MergeIsSet {
+		if t.Merge {
+			o.MergeMode = MergeCollections
+		} else {
+			o.MergeMode = ReplaceCollections
+		}
+	}
 	return o
 }
 
+// MergeMode determines how Unmarshal treats the existing values of slices,
+// arrays, prefixed maps and the container.
+type MergeMode int
+
+const (
+	// MergeDefault merges slices, arrays and prefixed maps while replacing the
+	// container.
+	MergeDefault MergeMode = iota
+	// MergeCollections appends to slices, assigns the leading elements of arrays
+	// and adds to maps and the container, overwriting keys which are present.
+	// Slices of structs with indexed keys are merged by index instead.
+	MergeCollections
+	// ReplaceCollections replaces slices, arrays, prefixed maps and the container
+	// outright.
+	ReplaceCollections
+)
+
+// KubernetesMode determines the Kubernetes rules, if any, which keys and
+// values are validated against.
+type KubernetesMode int
+
+const (
+	// KubernetesOff does not validate keys and values.
+	KubernetesOff KubernetesMode = iota
+	// KubernetesLabels validates keys and values as metadata.labels. Keys are an
+	// optional DNS subdomain prefix followed by "/" and a name of up to 63
+	// alphanumeric characters, '-', '_' or '.'. Values are limited to the same
+	// characters and length and may be empty.
+	KubernetesLabels
+	// KubernetesAnnotations validates keys and values as metadata.annotations.
+	// Keys are validated as they are for labels while values are unrestricted,
+	// aside from the total size of all keys and values, which is limited to
+	// 256 KiB.
+	KubernetesAnnotations
+)
+
+// AliasMatch describes a field which was unmarshaled from one of its aliases.
+type AliasMatch struct {
+	// Field is the path of the field
+	Field string
+	// Key is the field's primary key
+	Key string
+	// Alias is the alias that matched
+	Alias string
+}
+
 // Option is a function which accepts *Options, allowing for configuration
 type Option func(o *Options)
 
@@ -310,12 +546,87 @@ func OptDiscardLabels() Option {
 	}
 }
 
-// // OptRequireAllFields sets Options.Required to true, thus causing all fields with a tag to be required.
-// func OptRequireAllFields() Option {
-// 	return func(o *Options) {
-// 		o.RequireAllFields = true
-// 	}
-// }
+// OptRequireAllFields sets Options.RequireAllFields to true, thus causing all fields with a tag to be required.
+func OptRequireAllFields() Option {
+	return func(o *Options) {
+		o.RequireAllFields = true
+	}
+}
+
+// OptUseFirstValue sets Options.UseLastValue to false, assigning the first value of
+// multi-valued labels to scalar fields.
+func OptUseFirstValue() Option {
+	return func(o *Options) {
+		o.UseLastValue = false
+	}
+}
+
+// OptUseLastValue sets Options.UseLastValue to true, assigning the last value of
+// multi-valued labels to scalar fields.
+func OptUseLastValue() Option {
+	return func(o *Options) {
+		o.UseLastValue = true
+	}
+}
+
+// OptTransactional sets Transactional to true, causing Unmarshal to leave v
+// untouched if there are any errors.
+func OptTransactional() Option {
+	return func(o *Options) {
+		o.Transactional = true
+	}
+}
+
+// OptMerge sets MergeMode to MergeCollections
+func OptMerge() Option {
+	return func(o *Options) {
+		o.MergeMode = MergeCollections
+	}
+}
+
+// OptReplace sets MergeMode to ReplaceCollections
+func OptReplace() Option {
+	return func(o *Options) {
+		o.MergeMode = ReplaceCollections
+	}
+}
+
+// OptResetMissing sets ResetMissing to true, causing Unmarshal to reset fields
+// whose keys are not present to their zero or default value.
+func OptResetMissing() Option {
+	return func(o *Options) {
+		o.ResetMissing = true
+	}
+}
+
+// OptKubernetesLabels sets KubernetesMode to KubernetesLabels
+func OptKubernetesLabels() Option {
+	return func(o *Options) {
+		o.KubernetesMode = KubernetesLabels
+	}
+}
+
+// OptKubernetesAnnotations sets KubernetesMode to KubernetesAnnotations
+func OptKubernetesAnnotations() Option {
+	return func(o *Options) {
+		o.KubernetesMode = KubernetesAnnotations
+	}
+}
+
+// OptEnvPrefix sets EnvPrefix to v, such as "APP_"
+func OptEnvPrefix(v string) Option {
+	return func(o *Options) {
+		o.EnvPrefix = v
+	}
+}
+
+// OptEnviron sets Environ to environ, which UnmarshalEnv reads in place of
+// os.Environ()
+func OptEnviron(environ []string) Option {
+	return func(o *Options) {
+		o.Environ = environ
+	}
+}
 
 // OptSeparator sets the Separator option to s. This allows for tags to have a different separator string other than ","
 // such as MyField string `label:"mykey|default:has,commas"`
@@ -407,19 +718,191 @@ func OptFormatToken(v string) Option {
 	}
 }
 
-// // OptRequiredToken sets RequiredToken to v
-// func OptRequiredToken(v string) Option {
-// 	return func(o *Options) {
-// 		o.RequiredToken = v
-// 	}
-// }
+// OptRequiredToken sets RequiredToken to v
+func OptRequiredToken(v string) Option {
+	return func(o *Options) {
+		o.RequiredToken = v
+	}
+}
 
-// // OptNotRequiredToken sets NotRequiredToken to v
-// func OptNotRequiredToken(v string) Option {
-// 	return func(o *Options) {
-// 		o.NotRequiredToken = v
-// 	}
-// }
+// OptNotRequiredToken sets NotRequiredToken to v
+func OptNotRequiredToken(v string) Option {
+	return func(o *Options) {
+		o.NotRequiredToken = v
+	}
+}
+
+// OptPathDelimiter sets PathDelimiter to v, which is used to join the keys of
+// prefixed nested structs to the keys of their fields.
+func OptPathDelimiter(v string) Option {
+	return func(o *Options) {
+		o.PathDelimiter = v
+	}
+}
+
+// OptIndexFormat sets IndexFormat to v, which formats the indices of slices and
+// arrays of structs. v must contain %d.
+func OptIndexFormat(v string) Option {
+	return func(o *Options) {
+		o.IndexFormat = v
+	}
+}
+
+// OptPrefixToken sets PrefixToken to v
+func OptPrefixToken(v string) Option {
+	return func(o *Options) {
+		o.PrefixToken = v
+	}
+}
+
+// OptConverter registers encode and decode as the converter for fields (and
+// elements of slices and arrays) of type t, taking priority over the built-in
+// handling of t. v is of type t; pointers to t are dereferenced beforehand.
+// If t is a pointer type, the converter applies to fields of both t and the
+// type it points to, with v being a pointer to the field's value. Either encode
+// or decode may be nil if only one direction is needed.
+func OptConverter(t reflect.Type, encode EncodeFunc, decode DecodeFunc) Option {
+	return func(o *Options) {
+		conv := converter{encode: encode, decode: decode}
+		if t.Kind() == reflect.Ptr {
+			t, conv = t.Elem(), conv.addressed()
+		}
+		o.converters = o.converters.with(t, conv)
+	}
+}
+
+// OptToken registers a custom token, such as "mask" in `label:"name,mask"`.
+// parse, if not nil, parses the value assigned to the token (the raw value is
+// stored otherwise) into Tag.Extensions. hook is called for fields tagged with
+// the token when marshaling and unmarshaling. If token conflicts with a
+// built-in token, an OptionError is returned when marshaling and unmarshaling.
+func OptToken(token string, parse TokenParseFunc, hook TokenHook) Option {
+	return func(o *Options) {
+		o.customTokens = o.customTokens.with(token, customToken{parse: parse, hook: hook})
+	}
+}
+
+// OptNamingStrategy sets NamingStrategy to fn, deriving keys for untagged fields.
+func OptNamingStrategy(fn NamingStrategy) Option {
+	return func(o *Options) {
+		o.NamingStrategy = fn
+	}
+}
+
+// OptAliasToken sets AliasToken to v
+func OptAliasToken(v string) Option {
+	return func(o *Options) {
+		o.AliasToken = v
+	}
+}
+
+// OptLegacyToken sets LegacyToken to v
+func OptLegacyToken(v string) Option {
+	return func(o *Options) {
+		o.LegacyToken = v
+	}
+}
+
+// OptMinToken sets MinToken to v
+func OptMinToken(v string) Option {
+	return func(o *Options) {
+		o.MinToken = v
+	}
+}
+
+// OptMaxToken sets MaxToken to v
+func OptMaxToken(v string) Option {
+	return func(o *Options) {
+		o.MaxToken = v
+	}
+}
+
+// OptLenToken sets LenToken to v
+func OptLenToken(v string) Option {
+	return func(o *Options) {
+		o.LenToken = v
+	}
+}
+
+// OptOneOfToken sets OneOfToken to v
+func OptOneOfToken(v string) Option {
+	return func(o *Options) {
+		o.OneOfToken = v
+	}
+}
+
+// OptPatternToken sets PatternToken to v
+func OptPatternToken(v string) Option {
+	return func(o *Options) {
+		o.PatternToken = v
+	}
+}
+
+// OptMergeToken sets MergeToken to v
+func OptMergeToken(v string) Option {
+	return func(o *Options) {
+		o.MergeToken = v
+	}
+}
+
+// OptReplaceToken sets ReplaceToken to v
+func OptReplaceToken(v string) Option {
+	return func(o *Options) {
+		o.ReplaceToken = v
+	}
+}
+
+// OptDocToken sets DocToken to v
+func OptDocToken(v string) Option {
+	return func(o *Options) {
+		o.DocToken = v
+	}
+}
+
+// OptNonZeroToken sets NonZeroToken to v
+func OptNonZeroToken(v string) Option {
+	return func(o *Options) {
+		o.NonZeroToken = v
+	}
+}
+
+// OptAliasSeparator sets AliasSeparator to v
+func OptAliasSeparator(v string) Option {
+	return func(o *Options) {
+		o.AliasSeparator = v
+	}
+}
+
+// OptAliasHandler sets AliasHandler to fn, which is called whenever a field is
+// unmarshaled from one of its aliases.
+func OptAliasHandler(fn func(m AliasMatch)) Option {
+	return func(o *Options) {
+		o.AliasHandler = fn
+	}
+}
+
+// OptDisallowUnknownKeys sets DisallowUnknownKeys to true, causing Unmarshal to
+// return an UnknownKeysError if the input has keys which no field consumed.
+func OptDisallowUnknownKeys() Option {
+	return func(o *Options) {
+		o.DisallowUnknownKeys = true
+	}
+}
+
+// OptAllowUnknownKeys sets DisallowUnknownKeys to false
+func OptAllowUnknownKeys() Option {
+	return func(o *Options) {
+		o.DisallowUnknownKeys = false
+	}
+}
+
+// OptUnknownKeyHandler sets UnknownKeyHandler to fn, which is called for each
+// key in the input which no field consumed.
+func OptUnknownKeyHandler(fn func(k UnknownKey)) Option {
+	return func(o *Options) {
+		o.UnknownKeyHandler = fn
+	}
+}
 
 // OptIgnoreCaseToken sets the IgnoreCaseToken to v
 func OptIgnoreCaseToken(v string) Option {
@@ -505,6 +988,12 @@ func OptIntBase(v int) Option {
 	}
 }
 
+// NewOptions returns the default Options with opts applied. This is primarily
+// useful for calling MarshalLabels or UnmarshalLabels directly.
+func NewOptions(opts ...Option) Options {
+	return newOptions(opts)
+}
+
 func newOptions(opts []Option) Options {
 	o := getDefaultOptions()
 	if len(opts) > 0 {
@@ -513,6 +1002,7 @@ func newOptions(opts []Option) Options {
 		}
 		// o.tokenSensitivity()
 		o.tokenParsers = getTokenParsers(o)
+		o.customTokenErr = o.validateCustomTokens()
 	} else {
 		o.tokenParsers = defaultTokenParsers
 	}
@@ -554,6 +1044,31 @@ func (o Options) Validate() error {
 			}
 		}
 	}
+	if strings.Count(o.IndexFormat, "%d") != 1 {
+		return NewOptionError("IndexFormat", "must contain %d exactly once")
+	}
+	return o.validateCustomTokens()
+}
+
+// validateCustomTokens returns an OptionError if a token registered with
+// OptToken is empty or conflicts with a built-in token.
+func (o Options) validateCustomTokens() error {
+	if len(o.customTokens) == 0 {
+		return nil
+	}
+	rv := reflect.ValueOf(o)
+	rt := reflect.TypeOf(o)
+	tokens := map[string]bool{}
+	for i := 0; i < rt.NumField(); i++ {
+		if t, _ := rt.Field(i).Tag.Lookup("option"); t == "token" {
+			tokens[strings.TrimSpace(rv.Field(i).String())] = true
+		}
+	}
+	for token := range o.customTokens {
+		if token == "" || tokens[token] {
+			return NewOptionError(token, "custom token is empty or conflicts with a built-in token")
+		}
+	}
 	return nil
 }
 
@@ -567,3 +1082,14 @@ func isValidFloatFormat(f byte) bool {
 	}
 	return false
 }
+
+// pickValue returns the first or last of values, depending on UseLastValue.
+func (o Options) pickValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if o.UseLastValue {
+		return values[len(values)-1]
+	}
+	return values[0]
+}
@@ -0,0 +1,167 @@
+package labeler
+
+import (
+	"reflect"
+	"sync"
+)
+
+// plan is the compiled, value independent, representation of a type. Tags are
+// parsed and the marshal / unmarshal funcs are resolved once per reflect.Type
+// and then bound to each value passed to Marshal or Unmarshal.
+type plan struct {
+	marshal   marshalFunc
+	unmarshal unmarshalFunc
+	tagged    []*fieldPlan
+	container *fieldPlan
+	err       error
+}
+
+// fieldPlan is the compiled representation of a field. parent is nil for
+// fields that belong directly to the subject and is otherwise the plan of the
+// (untagged or prefixed) nested struct containing the field.
+type fieldPlan struct {
+	parent      *fieldPlan
+	index       int
+	name        string
+	path        string
+	key         string
+	keys        []string
+	legacyKey   string
+	mapPrefix   string
+	isIndexed   bool
+	tag         *Tag
+	isTagged    bool
+	isContainer bool
+	marshal     marshalFunc
+	unmarshal   unmarshalFunc
+}
+
+// planCache stores plans by reflect.Type. It is safe for concurrent use.
+type planCache struct {
+	plans sync.Map
+}
+
+func newPlanCache() *planCache {
+	return &planCache{}
+}
+
+func (pc *planCache) get(t reflect.Type, o Options) *plan {
+	if p, ok := pc.plans.Load(t); ok {
+		return p.(*plan)
+	}
+	p, _ := pc.plans.LoadOrStore(t, compilePlan(t, o))
+	return p.(*plan)
+}
+
+// subject binds rv, a pointer, to the cached plan for its type, compiling the
+// plan if this is the first time the type has been seen. If pc is nil, as it is
+// for Labelers which are used once, the subject is built from rv directly.
+func (pc *planCache) subject(rv reflect.Value, o Options) (subject, error) {
+	if pc == nil {
+		return newSubject(rv.Interface(), o)
+	}
+	return pc.get(rv.Type(), o).bind(rv.Interface())
+}
+
+// compilePlan builds a plan for t, which must be a pointer type, by parsing
+// a zero value of t.
+func compilePlan(t reflect.Type, o Options) *plan {
+	sub, err := newSubject(reflect.New(t.Elem()).Interface(), o)
+	p := &plan{
+		marshal:   sub.marshal,
+		unmarshal: sub.unmarshal,
+		tagged:    make([]*fieldPlan, 0, len(sub.tagged)),
+		err:       err,
+	}
+	if err != nil {
+		return p
+	}
+	compiled := make(map[*field]*fieldPlan)
+	for _, f := range sub.tagged {
+		p.tagged = append(p.tagged, newFieldPlan(f, compiled))
+	}
+	if sub.container != nil {
+		p.container = newFieldPlan(sub.container, compiled)
+	}
+	return p
+}
+
+func newFieldPlan(f *field, compiled map[*field]*fieldPlan) *fieldPlan {
+	if fp, ok := compiled[f]; ok {
+		return fp
+	}
+	fp := &fieldPlan{
+		index:       f.index,
+		name:        f.name,
+		path:        f.path,
+		key:         f.key,
+		keys:        f.keys,
+		legacyKey:   f.legacyKey,
+		mapPrefix:   f.mapPrefix,
+		isIndexed:   f.isIndexed,
+		tag:         f.tag,
+		isTagged:    f.isTagged,
+		isContainer: f.isContainer,
+		marshal:     f.marshal,
+		unmarshal:   f.unmarshal,
+	}
+	if parent, ok := f.parent.(*field); ok {
+		fp.parent = newFieldPlan(parent, compiled)
+	}
+	compiled[f] = fp
+	return fp
+}
+
+// bind creates a subject for v based upon the plan.
+func (p *plan) bind(v interface{}) (subject, error) {
+	if p.err != nil {
+		return subject{}, p.err
+	}
+	sub := subject{
+		meta:     newMeta(reflect.ValueOf(v)),
+		fieldset: fieldset{tagged: make([]*field, 0, len(p.tagged))},
+	}
+	sub.marshal = p.marshal
+	sub.unmarshal = p.unmarshal
+	bound := make(map[*fieldPlan]*field)
+	for _, fp := range p.tagged {
+		sub.tagged = append(sub.tagged, fp.bind(&sub, bound))
+	}
+	if p.container != nil {
+		sub.container = p.container.bind(&sub, bound)
+	}
+	return sub, nil
+}
+
+func (fp *fieldPlan) bind(sub *subject, bound map[*fieldPlan]*field) *field {
+	if f, ok := bound[fp]; ok {
+		return f
+	}
+	var parent reflected = sub
+	if fp.parent != nil {
+		parent = fp.parent.bind(sub, bound)
+	}
+	rv, ok := parent.ValueField(fp.index)
+	if !ok {
+		panic("labeler: plan does not match value")
+	}
+	f := &field{
+		meta:        newMeta(rv),
+		tag:         fp.tag,
+		parent:      parent,
+		index:       fp.index,
+		name:        fp.name,
+		path:        fp.path,
+		key:         fp.key,
+		keys:        fp.keys,
+		legacyKey:   fp.legacyKey,
+		mapPrefix:   fp.mapPrefix,
+		isIndexed:   fp.isIndexed,
+		isTagged:    fp.isTagged,
+		isContainer: fp.isContainer,
+	}
+	f.marshal = fp.marshal
+	f.unmarshal = fp.unmarshal
+	bound[fp] = f
+	return f
+}
@@ -0,0 +1,226 @@
+package labeler
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func init() {
+	// prefixed maps of structs create subjects for their elements, which would
+	// otherwise cause an initialization cycle through fieldMarshalers and
+	// fieldUnmarshalers.
+	fieldMarshalers = append(marshalerFuncs{marshalPrefixMap}, fieldMarshalers...)
+	fieldUnmarshalers = append(unmarshalerFuncs{unmarshalPrefixMap}, fieldUnmarshalers...)
+}
+
+// prefixedKeys returns the keys in kvs which start with f's map prefix, sorted.
+func (f *field) prefixedKeys(kvs *keyValues, o Options) []string {
+	keys := []string{}
+	n := len(f.mapPrefix)
+	for key := range kvs.Map() {
+		if len(key) <= n {
+			continue
+		}
+		if key[:n] == f.mapPrefix || (f.ignoreCase(o) && strings.EqualFold(key[:n], f.mapPrefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var unmarshalPrefixMap = func(r reflected, o Options) unmarshalFunc {
+	f, ok := r.(*field)
+	if !ok || f.mapPrefix == "" || !r.CanSet() || r.Kind() != reflect.Map || r.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	r.PrepCollection()
+	defer r.ResetCollection()
+
+	fn := collectionUnmarshalers.Unmarshaler(r, o)
+	if fn == nil && !r.IsStruct() {
+		return nil
+	}
+
+	return func(r reflected, kvs *keyValues, o Options) error {
+		f := r.(*field)
+		keys := f.prefixedKeys(kvs, o)
+		if len(keys) == 0 {
+			return f.validateAbsent(o)
+		}
+		prev := f.snapshotCollection()
+		if f.colValue.IsNil() || !f.merges(o) {
+			f.colValue.Set(reflect.MakeMap(f.colType))
+		}
+		r.PrepCollection()
+		defer r.ResetCollection()
+
+		var err error
+		if fn != nil {
+			err = f.unmarshalMapValues(keys, kvs, fn, o)
+		} else {
+			err = f.unmarshalMapStructs(keys, kvs, o)
+		}
+		if err != nil {
+			return err
+		}
+		f.wasSet = true
+		if f.ShouldDiscard(o) {
+			for _, key := range keys {
+				kvs.Delete(key)
+			}
+		}
+		return f.validateUnmarshaledCollection(prev, "", o)
+	}
+}
+
+func (f *field) unmarshalMapValues(keys []string, kvs *keyValues, fn unmarshalFunc, o Options) error {
+	for _, key := range keys {
+		kv, _ := kvs.Get(key, false)
+		rv := reflect.New(f.colElemType).Elem()
+		if err := unmarshalElem(f, rv, kv.Value, fn, o); err != nil {
+			return err
+		}
+		mk := reflect.ValueOf(key[len(f.mapPrefix):]).Convert(f.colType.Key())
+		f.colValue.SetMapIndex(mk, rv)
+	}
+	return nil
+}
+
+// unmarshalMapStructs groups keys by the segment following the map prefix,
+// such as "primary" in "db.primary.host", and unmarshals the remainder of each
+// key into the fields of a struct.
+func (f *field) unmarshalMapStructs(keys []string, kvs *keyValues, o Options) error {
+	delim := o.PathDelimiter
+	groups := map[string]*keyValues{}
+	order := []string{}
+	for _, key := range keys {
+		rest := key[len(f.mapPrefix):]
+		i := strings.Index(rest, delim)
+		if i <= 0 {
+			continue
+		}
+		name := rest[:i]
+		if _, ok := groups[name]; !ok {
+			nkvs := newKeyValues()
+			groups[name] = &nkvs
+			order = append(order, name)
+		}
+		kv, _ := kvs.Get(key, false)
+		groups[name].SetFrom(rest[i+len(delim):], kv.Value, key)
+	}
+	for _, name := range order {
+		mk := reflect.ValueOf(name).Convert(f.colType.Key())
+		rv := reflect.New(f.colElemType).Elem()
+		if existing := f.colValue.MapIndex(mk); existing.IsValid() {
+			rv.Set(existing)
+		}
+		if err := unmarshalStructElem(rv, groups[name], o); err != nil {
+			return nestFieldErrors(err, f.path+"["+name+"]", f.mapPrefix+name+delim)
+		}
+		kvs.MarkUsedFrom(groups[name])
+		f.colValue.SetMapIndex(mk, rv)
+	}
+	return nil
+}
+
+// unmarshalStructElem unmarshals kvs into the fields of rv, an addressable
+// struct or pointer to a struct which is allocated if nil.
+func unmarshalStructElem(rv reflect.Value, kvs *keyValues, o Options) error {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+	} else {
+		rv = rv.Addr()
+	}
+	sub, err := o.plans.subject(rv, o)
+	if err != nil {
+		return err
+	}
+	return sub.unmarshalFields(kvs, o)
+}
+
+var marshalPrefixMap = func(r reflected, o Options) marshalFunc {
+	f, ok := r.(*field)
+	if !ok || f.mapPrefix == "" || r.Kind() != reflect.Map || r.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	r.PrepCollection()
+	defer r.ResetCollection()
+
+	fn := collectionMarshalers.Marshaler(r, o)
+	if fn == nil && !r.IsStruct() {
+		return nil
+	}
+
+	return func(r reflected, kvs *keyValues, o Options) error {
+		f := r.(*field)
+		if err := f.validateCollection("", o); err != nil {
+			return err
+		}
+		names := []string{}
+		for _, k := range f.colValue.MapKeys() {
+			names = append(names, k.String())
+		}
+		sort.Strings(names)
+
+		r.PrepCollection()
+		defer r.ResetCollection()
+
+		for _, name := range names {
+			mv := f.colValue.MapIndex(reflect.ValueOf(name).Convert(f.colType.Key()))
+			rv := reflect.New(f.colElemType).Elem()
+			rv.Set(mv)
+			if fn != nil {
+				if err := f.marshalMapValue(name, rv, kvs, fn, o); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := marshalStructElem(rv, f.mapPrefix+name+o.PathDelimiter, kvs, o); err != nil {
+				return nestFieldErrors(err, f.path+"["+name+"]", f.mapPrefix+name+o.PathDelimiter)
+			}
+		}
+		return nil
+	}
+}
+
+func (f *field) marshalMapValue(name string, rv reflect.Value, kvs *keyValues, fn marshalFunc, o Options) error {
+	f.SetValue(rv)
+	f.deref()
+	nkvs := newKeyValues()
+	if err := fn(f, &nkvs, o); err != nil {
+		return err
+	}
+	if kv, ok := nkvs.Get(f.key, false); ok {
+		kvs.Set(f.mapPrefix+name, kv.Value)
+	}
+	return nil
+}
+
+// marshalStructElem marshals the fields of rv, an addressable struct or
+// pointer to a struct, into kvs with their keys prefixed by prefix. Nil
+// pointers are skipped.
+func marshalStructElem(rv reflect.Value, prefix string, kvs *keyValues, o Options) error {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+	} else {
+		rv = rv.Addr()
+	}
+	sub, err := o.plans.subject(rv, o)
+	if err != nil {
+		return err
+	}
+	nkvs := newKeyValues()
+	if err := sub.marshalFields(&nkvs, o); err != nil {
+		return err
+	}
+	for k, v := range nkvs.Map() {
+		kvs.Set(prefix+k, v)
+	}
+	return nil
+}
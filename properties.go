@@ -0,0 +1,226 @@
+package labeler
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// UnmarshalProperties parses the Java .properties file read from r and
+// unmarshals its entries into v. Keys are separated from values by "=", ":" or
+// whitespace. Lines starting with "#" or "!" are comments and lines ending
+// with an odd number of backslashes are continued on the next line, with its
+// leading whitespace removed. Keys and values may contain the escapes \t, \n,
+// \r, \f and \uXXXX; a backslash preceding any other character is removed. v
+// does not need a container.
+func UnmarshalProperties(r io.Reader, v interface{}, opts ...Option) error {
+	lbl := newLabeler(opts)
+	return lbl.UnmarshalProperties(r, v)
+}
+
+// MarshalProperties writes v to w as a Java .properties file, with the keys
+// of each field in the order the fields are declared, preceded by the field's
+// DocToken as a comment, followed by the remaining labels sorted by key.
+// Entries are written as key=value. Characters outside of printable ASCII are
+// written as \uXXXX escapes and values containing newlines are continued on
+// the following lines.
+func MarshalProperties(w io.Writer, v interface{}, opts ...Option) error {
+	lbl := newLabeler(opts)
+	return lbl.MarshalProperties(w, v)
+}
+
+// UnmarshalProperties parses the .properties file read from r into v using the
+// Options provided to Labeler. See UnmarshalProperties.
+func (lbl *Labeler) UnmarshalProperties(r io.Reader, v interface{}) error {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m, err := parseProperties(string(src))
+	if err != nil {
+		return err
+	}
+	o := lbl.options
+	o.containerOptional = true
+	return lbl.unmarshal(m, v, o)
+}
+
+// MarshalProperties writes v to w as a .properties file using the Options
+// provided to Labeler. See MarshalProperties.
+func (lbl *Labeler) MarshalProperties(w io.Writer, v interface{}) error {
+	entries, _, err := lbl.marshalEntries(v)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		if e.Doc != "" {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("# " + e.Doc + "\n")
+		}
+		b.WriteString(escapeProperty(e.Key, true) + "=" + escapeProperty(e.Value, false) + "\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func parseProperties(src string) (map[string]string, error) {
+	src = strings.Replace(src, "\r\n", "\n", -1)
+	lines := strings.Split(strings.Replace(src, "\r", "\n", -1), "\n")
+	m := map[string]string{}
+	for i := 0; i < len(lines); i++ {
+		n := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		for continues(line) {
+			line = line[:len(line)-1]
+			if i+1 == len(lines) {
+				break
+			}
+			i++
+			line += strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		j := 0
+		for ; j < len(line); j++ {
+			if line[j] == '\\' {
+				j++
+				continue
+			}
+			if strings.IndexByte("=: \t\f", line[j]) >= 0 {
+				break
+			}
+		}
+		if j > len(line) {
+			j = len(line)
+		}
+		rest := strings.TrimLeft(line[j:], " \t\f")
+		if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+			rest = strings.TrimLeft(rest[1:], " \t\f")
+		}
+		key, err := unescapeProperty(line[:j])
+		if err != nil {
+			return nil, &SyntaxError{Format: "properties", Line: n, Err: err}
+		}
+		value, err := unescapeProperty(rest)
+		if err != nil {
+			return nil, &SyntaxError{Format: "properties", Line: n, Err: err}
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+// continues reports whether line ends with an odd number of backslashes.
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func unescapeProperty(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	var units []uint16
+	flush := func() {
+		if len(units) > 0 {
+			b.WriteString(string(utf16.Decode(units)))
+			units = units[:0]
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			flush()
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			break
+		}
+		if s[i] == 'u' {
+			if i+5 > len(s) {
+				return "", fmt.Errorf("%w: malformed \\uXXXX escape", ErrSyntax)
+			}
+			u, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("%w: malformed \\uXXXX escape", ErrSyntax)
+			}
+			// consecutive escapes are decoded together so that surrogate
+			// pairs form a single rune
+			units = append(units, uint16(u))
+			i += 4
+			continue
+		}
+		flush()
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	flush()
+	return b.String(), nil
+}
+
+// escapeProperty escapes s as a key, in which every space is escaped, or a
+// value, in which only leading spaces are escaped. Newlines in values are
+// followed by a line continuation.
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	lineStart := true
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if isKey || lineStart {
+				b.WriteString(`\ `)
+				continue
+			}
+			b.WriteByte(' ')
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+			if !isKey && i+1 < len(s) {
+				b.WriteString("\\\n    ")
+				lineStart = true
+				continue
+			}
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':', '#', '!', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			if r < 0x20 || r > 0x7e {
+				for _, u := range utf16.Encode([]rune{r}) {
+					fmt.Fprintf(&b, `\u%04X`, u)
+				}
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		lineStart = false
+	}
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package labeler
+
+import (
+	"math/big"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+func (f *field) formatIP(o Options) (string, error) {
+	if v, ok := f.Interface().(*net.IP); ok && v != nil && len(*v) > 0 {
+		return v.String(), nil
+	}
+	return "", nil
+}
+
+func (f *field) setIP(s string, o Options) error {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return f.err(&net.ParseError{Type: "IP address", Text: s})
+	}
+	v := f.value.Addr().Interface().(*net.IP)
+	*v = ip
+	return nil
+}
+
+func (f *field) formatIPNet(o Options) (string, error) {
+	if v, ok := f.Interface().(*net.IPNet); ok && v != nil && v.IP != nil {
+		return v.String(), nil
+	}
+	return "", nil
+}
+
+func (f *field) setIPNet(s string, o Options) error {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return f.err(err)
+	}
+	v := f.value.Addr().Interface().(*net.IPNet)
+	*v = *n
+	return nil
+}
+
+func (f *field) formatURL(o Options) (string, error) {
+	if v, ok := f.Interface().(*url.URL); ok && v != nil {
+		return v.String(), nil
+	}
+	return "", nil
+}
+
+func (f *field) setURL(s string, o Options) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return f.err(err)
+	}
+	v := f.value.Addr().Interface().(*url.URL)
+	*v = *u
+	return nil
+}
+
+func (f *field) formatRegexp(o Options) (string, error) {
+	if v, ok := f.Interface().(*regexp.Regexp); ok && v != nil {
+		return v.String(), nil
+	}
+	return "", nil
+}
+
+func (f *field) setRegexp(s string, o Options) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return f.err(err)
+	}
+	v := f.value.Addr().Interface().(*regexp.Regexp)
+	*v = *re
+	return nil
+}
+
+func (f *field) formatBigInt(o Options) (string, error) {
+	if v, ok := f.Interface().(*big.Int); ok && v != nil {
+		return v.Text(f.intBase(o)), nil
+	}
+	return "", nil
+}
+
+func (f *field) setBigInt(s string, o Options) error {
+	v := f.value.Addr().Interface().(*big.Int)
+	if _, ok := v.SetString(s, f.intBase(o)); !ok {
+		return f.err(&strconv.NumError{Func: "SetString", Num: s, Err: strconv.ErrSyntax})
+	}
+	return nil
+}
+
+func (f *field) formatBigFloat(o Options) (string, error) {
+	if v, ok := f.Interface().(*big.Float); ok && v != nil {
+		return v.Text(f.floatFormat(o), -1), nil
+	}
+	return "", nil
+}
+
+func (f *field) setBigFloat(s string, o Options) error {
+	v := f.value.Addr().Interface().(*big.Float)
+	if _, _, err := v.Parse(s, 0); err != nil {
+		return f.err(err)
+	}
+	return nil
+}
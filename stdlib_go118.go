@@ -0,0 +1,59 @@
+//go:build go1.18
+// +build go1.18
+
+package labeler
+
+import "net/netip"
+
+func init() {
+	fieldStringerPkgs["net/netip"] = map[string]fieldStringer{
+		"Addr": func(f *field, o Options) (string, error) {
+			return f.formatNetipAddr(o)
+		},
+		"Prefix": func(f *field, o Options) (string, error) {
+			return f.formatNetipPrefix(o)
+		},
+	}
+	fieldStringeePkgs["net/netip"] = map[string]fieldStrUnmarshalFunc{
+		"Addr": func(f *field, s string, o Options) error {
+			return f.setNetipAddr(s, o)
+		},
+		"Prefix": func(f *field, s string, o Options) error {
+			return f.setNetipPrefix(s, o)
+		},
+	}
+}
+
+func (f *field) formatNetipAddr(o Options) (string, error) {
+	if v, ok := f.Interface().(*netip.Addr); ok && v.IsValid() {
+		return v.String(), nil
+	}
+	return "", nil
+}
+
+func (f *field) setNetipAddr(s string, o Options) error {
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return f.err(err)
+	}
+	v := f.value.Addr().Interface().(*netip.Addr)
+	*v = addr
+	return nil
+}
+
+func (f *field) formatNetipPrefix(o Options) (string, error) {
+	if v, ok := f.Interface().(*netip.Prefix); ok && v.IsValid() {
+		return v.String(), nil
+	}
+	return "", nil
+}
+
+func (f *field) setNetipPrefix(s string, o Options) error {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return f.err(err)
+	}
+	v := f.value.Addr().Interface().(*netip.Prefix)
+	*v = prefix
+	return nil
+}
@@ -3,7 +3,6 @@ package labeler
 import (
 	"errors"
 	"reflect"
-	"sync"
 )
 
 type subject struct {
@@ -17,6 +16,9 @@ func newSubject(v interface{}, o Options) (subject, error) {
 	if rv.Kind() != reflect.Ptr {
 		return subject{}, ErrInvalidValue
 	}
+	if o.customTokenErr != nil {
+		return subject{}, o.customTokenErr
+	}
 	sub := subject{
 		meta:     newMeta(rv),
 		fieldset: newFieldset(),
@@ -24,44 +26,56 @@ func newSubject(v interface{}, o Options) (subject, error) {
 
 	sub.marshal = getMarshal(&sub, o)
 	sub.unmarshal = getUnmarshal(&sub, o)
+	if sub.Implements(generatedType) {
+		return sub, nil
+	}
 	err := sub.init(o)
 	return sub, err
 }
 
 func (sub *subject) init(o Options) error {
-	ch := newChannels(sub, o)
-	go ch.processFields()
 	errs := []*FieldError{}
-	fieldCh := ch.fieldCh
-	errCh := ch.errCh
-	for fieldCh != nil || errCh != nil {
-		select {
-		case f, ok := <-fieldCh:
-			if !ok {
-				fieldCh = nil
-				break
+	err := sub.walk(sub, o, &errs)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return NewParsingError(errs)
+	}
+	return nil
+}
+
+// walk visits the fields of r in declaration order, descending into prefixed
+// and untagged, accessible nested structs. FieldErrors are collected into errs so that all
+// of them are reported; any other error halts the walk.
+func (sub *subject) walk(r reflected, o Options, errs *[]*FieldError) error {
+	numField := r.NumField()
+	for i := 0; i < numField; i++ {
+		f, err := newField(r, i, o)
+		if err != nil {
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				return err
 			}
-			err := sub.processField(f, o)
-			if err != nil {
+			*errs = append(*errs, fieldErr)
+			continue
+		}
+		switch {
+		case f.isIgnored:
+		case f.isPrefix:
+			if err := sub.walk(f, o, errs); err != nil {
 				return err
 			}
-		case err, ok := <-errCh:
-			if !ok {
-				errCh = nil
-				break
+		case f.isTagged || f.isContainer:
+			if err := sub.processField(f, o); err != nil {
+				return err
 			}
-			var fieldErr *FieldError
-			if errors.As(err, &fieldErr) {
-				errs = append(errs, fieldErr)
-			} else {
+		case f.IsStruct() && f.canInterface:
+			if err := sub.walk(f, o, errs); err != nil {
 				return err
 			}
 		}
 	}
-	if len(errs) > 0 {
-		return NewParsingError(errs)
-	}
-	o = o.FromTag(sub.containerTag())
 	return nil
 }
 
@@ -70,49 +84,149 @@ func (sub *subject) Save() {
 }
 
 func (sub *subject) Unmarshal(kvs *keyValues, o Options) error {
-	if sub.unmarshal == nil && (sub.container == nil || sub.container.unmarshal == nil) {
+	if sub.unmarshal == nil && (sub.container == nil || sub.container.unmarshal == nil) && !o.containerOptional {
 		return ErrMissingContainer
 	}
+	o = o.FromTag(sub.containerTag())
+	if err := sub.validateKubernetes(kvs, OpUnmarshal, o); err != nil {
+		return err
+	}
+	var keys []string
+	if o.DisallowUnknownKeys || o.UnknownKeyHandler != nil {
+		// consumed keys may be discarded, so the input keys are captured first
+		keys = kvs.Keys()
+	}
+	if err := sub.unmarshalFields(kvs, o); err != nil {
+		return err
+	}
+	if err := sub.checkUnknownKeys(keys, kvs, o); err != nil {
+		return err
+	}
+	if sub.unmarshal != nil {
+		return sub.subjectErr(sub.unmarshal(sub, kvs, o), OpUnmarshal)
+	}
+	if sub.container == nil || sub.container.unmarshal == nil {
+		return nil
+	}
+	sub.container.op = OpUnmarshal
+	return sub.containerErr(sub.container.Unmarshal(kvs, o))
+}
+
+// containerErr wraps err, returned from the container, in a ParsingError so
+// that it is reported with the container's context like other fields.
+func (sub *subject) containerErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	return NewParsingError([]*FieldError{sub.container.err(err)})
+}
+
+// subjectErr wraps err, returned from sub's own implementation of an interface
+// such as StrictLabelee or Unmarshaler, in a ParsingError so that it is
+// reported with the context of sub's type like the errors of fields. Errors
+// which already describe fields, such as those of generated types, are
+// returned as is.
+func (sub *subject) subjectErr(err error, op Op) error {
+	if err == nil {
+		return nil
+	}
+	var pErr *ParsingError
+	var fieldErr *FieldError
+	if errors.As(err, &pErr) || errors.As(err, &fieldErr) {
+		return err
+	}
+	return NewParsingError([]*FieldError{{
+		Field: sub.TypeName(),
+		Type:  sub.Type().String(),
+		Op:    op,
+		Err:   err,
+	}})
+}
+
+// unmarshalFields unmarshals the tagged fields of sub, leaving the container
+// untouched.
+func (sub *subject) unmarshalFields(kvs *keyValues, o Options) error {
 	fieldErrs := []*FieldError{}
 	for _, f := range sub.tagged {
+		f.op = OpUnmarshal
+		if f.Required(o) && !f.HasDefault(o) {
+			if !f.isPresent(kvs, o) {
+				fieldErrs = append(fieldErrs, f.err(ErrLabelRequired))
+				continue
+			}
+		}
+		missing := o.ResetMissing && !f.HasDefault(o) && !f.isPresent(kvs, o)
 		err := f.Unmarshal(kvs, o)
-		if err != nil {
+		if nested, ok := f.nestedErrors(err); ok {
+			fieldErrs = append(fieldErrs, nested...)
+		} else if err != nil {
 			fieldErrs = append(fieldErrs, f.err(err))
+		} else if missing {
+			f.reset()
 		}
 		if f.ShouldDiscard(o) {
-			kvs.Delete(f.key)
+			for _, key := range f.keys {
+				kvs.Delete(key)
+			}
 		}
-		if f.wasSet {
+		if f.wasSet && err == nil {
 			f.Save()
 		}
 	}
 	if len(fieldErrs) > 0 {
 		return NewParsingError(fieldErrs)
 	}
-	if sub.unmarshal != nil {
-		return sub.unmarshal(sub, kvs, o)
-	}
-	return sub.container.Unmarshal(kvs, o)
+	return nil
 }
 
 func (sub *subject) Marshal(kvs *keyValues, o Options) error {
-	if sub.marshal == nil && (sub.container == nil || sub.container.marshal == nil) {
+	if sub.marshal == nil && (sub.container == nil || sub.container.marshal == nil) && !o.containerOptional {
 		return ErrMissingContainer
 	}
+	o = o.FromTag(sub.containerTag())
+	// labels from the container are marshaled first so that tagged field
+	// values take precedence.
+	var err error
+	if sub.marshal != nil {
+		err = sub.subjectErr(sub.marshal(sub, kvs, o), OpMarshal)
+	} else if sub.container != nil && sub.container.marshal != nil {
+		sub.container.op = OpMarshal
+		err = sub.containerErr(sub.container.Marshal(kvs, o))
+	}
+	if err != nil {
+		return err
+	}
+	if err := sub.marshalFields(kvs, o); err != nil {
+		return err
+	}
+	return sub.validateKubernetes(kvs, OpMarshal, o)
+}
+
+// marshalFields marshals the tagged fields of sub, leaving the container
+// untouched.
+func (sub *subject) marshalFields(kvs *keyValues, o Options) error {
 	fieldErrs := []*FieldError{}
 	for _, f := range sub.tagged {
+		f.op = OpMarshal
 		err := f.Marshal(kvs, o)
+		if nested, ok := f.nestedErrors(err); ok {
+			fieldErrs = append(fieldErrs, nested...)
+			continue
+		}
 		if err != nil {
 			fieldErrs = append(fieldErrs, f.err(err))
+			continue
+		}
+		if f.legacyKey != "" {
+			if kv, ok := kvs.Get(f.key, false); ok {
+				kvs.Set(f.legacyKey, kv.Value)
+			}
 		}
 	}
 	if len(fieldErrs) > 0 {
 		return NewParsingError(fieldErrs)
 	}
-	if sub.marshal != nil {
-		return sub.marshal(sub, kvs, o)
-	}
-	return sub.container.Marshal(kvs, o)
+	return nil
 }
 
 func (sub *subject) Path() string {
@@ -126,85 +240,3 @@ func (sub *subject) Topic() topic {
 func (sub *subject) IsContainer(o Options) bool {
 	return false
 }
-
-// should rename this
-type channels struct {
-	fieldCh   chan *field
-	errCh     chan error
-	reflected reflected
-	waitGroup *sync.WaitGroup
-	options   Options
-}
-
-func newChannels(r reflected, o Options) channels {
-	numField := r.NumField()
-	wg := &sync.WaitGroup{}
-	wg.Add(numField)
-	ch := channels{
-		reflected: r,
-		options:   o,
-		fieldCh:   make(chan *field, numField),
-		errCh:     make(chan error, numField),
-		waitGroup: wg,
-	}
-	return ch
-}
-
-func (ch channels) pipe(w channels) {
-	errCh := ch.errCh
-	fieldCh := ch.fieldCh
-	for errCh != nil || fieldCh != nil {
-		select {
-		case err, ok := <-errCh:
-			if !ok {
-				errCh = nil
-				break
-			}
-			w.errCh <- err
-		case f, ok := <-fieldCh:
-			if !ok {
-				fieldCh = nil
-				break
-			}
-			w.fieldCh <- f
-		}
-	}
-}
-
-func (ch channels) finished() {
-	ch.waitGroup.Wait()
-	close(ch.errCh)
-	close(ch.fieldCh)
-}
-
-func (ch channels) processFields() {
-	defer ch.finished()
-	ch.waitGroup.Add(1)
-	numField := ch.reflected.NumField()
-	for i := 0; i < numField; i++ {
-		go ch.processField(i)
-	}
-	ch.waitGroup.Done()
-}
-
-func (ch channels) processField(i int) {
-	defer ch.waitGroup.Done()
-	f, err := newField(ch.reflected, i, ch.options)
-	if err != nil {
-		ch.errCh <- err
-		return
-	}
-	switch {
-	case f.isTagged:
-		ch.fieldCh <- f
-	case f.IsContainer(ch.options):
-		ch.fieldCh <- f
-	case f.IsStruct() && f.canInterface:
-		wch := newChannels(f, ch.options)
-		go wch.processFields()
-		wch.pipe(ch)
-		// wch.waitGroup.Wait()
-	default:
-		ch.fieldCh <- f
-	}
-}
@@ -1,6 +1,7 @@
 package labeler
 
 import (
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -31,6 +32,31 @@ type Tag struct {
 	OmitEmptyIsSet    bool
 	IncludeEmptyIsSet bool
 	Split             string
+	MapPrefix         string
+	Prefix            bool
+	KeyPrefix         string
+	Aliases           []string
+	Legacy            string
+	Min               string
+	Max               string
+	Len               string
+	OneOf             []string
+	Pattern           string
+	NonZero           bool
+	Merge             bool
+	MergeIsSet        bool
+	Doc               string
+	// Extensions holds the parsed values of custom tokens registered with
+	// OptToken, keyed by token.
+	Extensions      map[string]interface{}
+	pattern         *regexp.Regexp
+	extensionTokens []string
+}
+
+// ParseTag parses the value of a struct tag (the portion within the quotes)
+// with the Options provided.
+func ParseTag(tag string, opts ...Option) (*Tag, error) {
+	return newTag(tag, newOptions(opts))
 }
 
 // NewTag creates a new Tag from a string and Options.
@@ -49,6 +75,9 @@ func newTag(tagStr string, o Options) (*Tag, error) {
 
 	if t.Key == o.ContainerToken {
 		t.IsContainer = true
+	} else if strings.HasSuffix(t.Key, o.ContainerToken) {
+		// keys such as "limit.*" collect every label starting with "limit."
+		t.MapPrefix = strings.TrimSuffix(t.Key, o.ContainerToken)
 	}
 	if len(tokens) == 1 {
 		return t, nil
@@ -131,6 +160,12 @@ func (t Tag) GetSplit() (string, bool) {
 	return "", false
 }
 
+// GetExtension returns the parsed value of the custom token if it is on the tag
+func (t Tag) GetExtension(token string) (interface{}, bool) {
+	v, ok := t.Extensions[token]
+	return v, ok
+}
+
 // SetIgnoreCase set' the field's or container's IgnoreCase for label keys
 func (t *Tag) setIgnoreCase(v bool) error {
 	if t.IgnoreCaseIsSet {
@@ -141,15 +176,15 @@ func (t *Tag) setIgnoreCase(v bool) error {
 	return nil
 }
 
-// // SetRequired sets the field's or container's Require / RequireAlLFields (respectively) for labels
-// func (t *Tag) setRequired(v bool) error {
-// 	if t.RequiredIsSet {
-// 		return ErrMalformedTag
-// 	}
-// 	t.Required = v
-// 	t.RequiredIsSet = true
-// 	return nil
-// }
+// SetRequired sets the field's or container's Require / RequireAllFields (respectively) for labels
+func (t *Tag) setRequired(v bool) error {
+	if t.RequiredIsSet {
+		return ErrMalformedTag
+	}
+	t.Required = v
+	t.RequiredIsSet = true
+	return nil
+}
 
 // SetKeep sets the field's or container's Keep / Discard of labels
 func (t *Tag) setKeep(v bool) error {
@@ -161,6 +196,17 @@ func (t *Tag) setKeep(v bool) error {
 	return nil
 }
 
+// setMerge sets whether the field's or container's existing value is merged
+// with or replaced by the labels
+func (t *Tag) setMerge(v bool) error {
+	if t.MergeIsSet {
+		return ErrMalformedTag
+	}
+	t.Merge = v
+	t.MergeIsSet = true
+	return nil
+}
+
 func (t *Tag) setOmitEmpty() error {
 	if t.OmitEmptyIsSet {
 		return ErrMalformedTag
@@ -256,6 +302,129 @@ func (t *Tag) setUintBase(s string) error {
 	//int
 }
 
+// SetPrefix marks the field as a prefix for its nested fields, setting the
+// value prepended to the field's keys if there is one
+func (t *Tag) setPrefix(s string) error {
+	if t.Prefix {
+		return ErrMalformedTag
+	}
+	t.Prefix = true
+	t.KeyPrefix = s
+	return nil
+}
+
+// SetAliases sets the field's alternate keys
+func (t *Tag) setAliases(s string, o Options) error {
+	if t.Aliases != nil || s == "" {
+		return ErrMalformedTag
+	}
+	for _, alias := range strings.Split(s, o.AliasSeparator) {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			return ErrMalformedTag
+		}
+		t.Aliases = append(t.Aliases, alias)
+	}
+	return nil
+}
+
+// SetLegacy sets the additional key the field is marshaled to
+func (t *Tag) setLegacy(s string) error {
+	if t.Legacy != "" || s == "" {
+		return ErrMalformedTag
+	}
+	t.Legacy = s
+	return nil
+}
+
+// setDoc sets the description of the field
+func (t *Tag) setDoc(s string) error {
+	if t.Doc != "" || s == "" {
+		return ErrMalformedTag
+	}
+	t.Doc = s
+	return nil
+}
+
+// SetExtension sets the parsed value of a custom token
+func (t *Tag) setExtension(token string, v interface{}) error {
+	if _, ok := t.Extensions[token]; ok {
+		return ErrMalformedTag
+	}
+	if t.Extensions == nil {
+		t.Extensions = make(map[string]interface{})
+	}
+	t.Extensions[token] = v
+	t.extensionTokens = append(t.extensionTokens, token)
+	return nil
+}
+
+// SetMin sets the field's lower bound
+func (t *Tag) setMin(s string) error {
+	if t.Min != "" || s == "" {
+		return ErrMalformedTag
+	}
+	t.Min = s
+	return nil
+}
+
+// SetMax sets the field's upper bound
+func (t *Tag) setMax(s string) error {
+	if t.Max != "" || s == "" {
+		return ErrMalformedTag
+	}
+	t.Max = s
+	return nil
+}
+
+// SetLen sets the field's exact length
+func (t *Tag) setLen(s string) error {
+	if t.Len != "" {
+		return ErrMalformedTag
+	}
+	if n, err := strconv.Atoi(s); err != nil || n < 0 {
+		return ErrMalformedTag
+	}
+	t.Len = s
+	return nil
+}
+
+// oneOfSeparator separates the values of the oneof token. It is fixed so that
+// changing AliasSeparator does not alter how oneof is parsed.
+const oneOfSeparator = "|"
+
+// SetOneOf sets the values permitted for the field
+func (t *Tag) setOneOf(s string) error {
+	if t.OneOf != nil || s == "" {
+		return ErrMalformedTag
+	}
+	t.OneOf = strings.Split(s, oneOfSeparator)
+	return nil
+}
+
+// SetPattern sets the regular expression the field's value must match
+func (t *Tag) setPattern(s string) error {
+	if t.Pattern != "" || s == "" {
+		return ErrMalformedTag
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return ErrMalformedTag
+	}
+	t.Pattern = s
+	t.pattern = re
+	return nil
+}
+
+// SetNonZero requires the field's value to not be the zero value
+func (t *Tag) setNonZero() error {
+	if t.NonZero {
+		return ErrMalformedTag
+	}
+	t.NonZero = true
+	return nil
+}
+
 func (t *Tag) setSplit(s string) error {
 	if len(s) == 0 {
 		return ErrSplitEmpty
@@ -303,7 +472,7 @@ func parseToken(t *Tag, s string, o Options) error {
 }
 
 func getTokenParsers(o Options) tagTokenParsers {
-	return tagTokenParsers{
+	parsers := tagTokenParsers{
 		o.IgnoreCaseToken:    parseIgnoreCase,
 		o.CaseSensitiveToken: parseCaseSensitive,
 		o.DiscardToken:       parseDiscard,
@@ -319,16 +488,66 @@ func getTokenParsers(o Options) tagTokenParsers {
 		o.IncludeEmptyToken:  parseIncludeEmpty,
 		o.OmitEmptyToken:     parseOmitEmpty,
 		o.SplitToken:         parseSplit,
-		// o.RequiredToken:      parseRequired,
-		// o.NotRequiredToken:   parseNotRquired,
+		o.RequiredToken:      parseRequired,
+		o.NotRequiredToken:   parseNotRequired,
+		o.PrefixToken:        parsePrefix,
+		o.AliasToken:         parseAlias,
+		o.LegacyToken:        parseLegacy,
+		o.MinToken:           parseMin,
+		o.MaxToken:           parseMax,
+		o.LenToken:           parseLen,
+		o.OneOfToken:         parseOneOf,
+		o.PatternToken:       parsePattern,
+		o.NonZeroToken:       parseNonZero,
+		o.MergeToken:         parseMerge,
+		o.ReplaceToken:       parseReplace,
+		o.DocToken:           parseDoc,
 	}
-
+	// custom tokens can not replace those built in; the conflict is returned
+	// when a subject is created. See Options.validateCustomTokens
+	for token, ct := range o.customTokens {
+		if _, ok := parsers[token]; !ok {
+			parsers[token] = ct.parser(token)
+		}
+	}
+	return parsers
 }
 
 var parseSplit = func(t *Tag, tt tagToken, o Options) error {
 	return t.setSplit(tt.value)
 }
 
+var parseAlias = func(t *Tag, tt tagToken, o Options) error {
+	return t.setAliases(tt.value, o)
+}
+var parseLegacy = func(t *Tag, tt tagToken, o Options) error {
+	return t.setLegacy(tt.value)
+}
+var parseDoc = func(t *Tag, tt tagToken, o Options) error {
+	return t.setDoc(tt.value)
+}
+var parseMin = func(t *Tag, tt tagToken, o Options) error {
+	return t.setMin(tt.value)
+}
+var parseMax = func(t *Tag, tt tagToken, o Options) error {
+	return t.setMax(tt.value)
+}
+var parseLen = func(t *Tag, tt tagToken, o Options) error {
+	return t.setLen(tt.value)
+}
+var parseOneOf = func(t *Tag, tt tagToken, o Options) error {
+	return t.setOneOf(tt.value)
+}
+var parsePattern = func(t *Tag, tt tagToken, o Options) error {
+	return t.setPattern(tt.value)
+}
+var parseNonZero = func(t *Tag, tt tagToken, o Options) error {
+	return t.setNonZero()
+}
+var parsePrefix = func(t *Tag, tt tagToken, o Options) error {
+	return t.setPrefix(tt.value)
+}
+
 var parseIncludeEmpty = func(t *Tag, tt tagToken, o Options) error {
 	return t.setIncludeEmpty()
 }
@@ -357,7 +576,7 @@ var parseTimeFormat = func(t *Tag, tt tagToken, o Options) error {
 	return t.setTimeFormat(tt.value)
 }
 var parseIgnoreCase = func(t *Tag, tt tagToken, o Options) error {
-	return t.setIgnoreCase(false)
+	return t.setIgnoreCase(true)
 }
 var parseCaseSensitive = func(t *Tag, tt tagToken, o Options) error {
 	return t.setIgnoreCase(false)
@@ -368,14 +587,19 @@ var parseDiscard = func(t *Tag, tt tagToken, o Options) error {
 var parseKeep = func(t *Tag, tt tagToken, o Options) error {
 	return t.setKeep(true)
 }
+var parseMerge = func(t *Tag, tt tagToken, o Options) error {
+	return t.setMerge(true)
+}
+var parseReplace = func(t *Tag, tt tagToken, o Options) error {
+	return t.setMerge(false)
+}
 var parseFormat = func(t *Tag, tt tagToken, o Options) error {
 	return t.setFormat(tt.value)
 }
 
-// var parseRequired  = func(t *Tag, tt tagToken, o Options) error {
-// 	return t.setRequired(true)
-// }
-
-// var parseNotRquired  = func(t *Tag, tt tagToken, o Options) error {
-// 	return t.setRequired(false)
-// }
+var parseRequired = func(t *Tag, tt tagToken, o Options) error {
+	return t.setRequired(true)
+}
+var parseNotRequired = func(t *Tag, tt tagToken, o Options) error {
+	return t.setRequired(false)
+}
@@ -0,0 +1,81 @@
+package labeler
+
+// TokenParseFunc parses the value assigned to a custom token, if any, such as
+// "crc32" for `label:"id,checksum:crc32"`. The result is stored in
+// Tag.Extensions under the token.
+type TokenParseFunc func(value string, t *Tag, o Options) (interface{}, error)
+
+// TokenHookFunc is called with the label value s of a field tagged with a
+// custom token along with the token's parsed value. The string returned
+// replaces s.
+type TokenHookFunc func(s string, ext interface{}, tag Tag, o Options) (string, error)
+
+// TokenHook is the field-level behavior of a custom token. Unmarshal is called
+// before a label value is converted and Marshal after a value is formatted. For
+// slices and arrays, they are called for each element. Either may be nil.
+type TokenHook struct {
+	Unmarshal TokenHookFunc
+	Marshal   TokenHookFunc
+}
+
+type customToken struct {
+	parse TokenParseFunc
+	hook  TokenHook
+}
+
+type customTokens map[string]customToken
+
+// with returns a copy of c with ct registered for token so that Options
+// sharing c are unaffected.
+func (c customTokens) with(token string, ct customToken) customTokens {
+	res := make(customTokens, len(c)+1)
+	for k, v := range c {
+		res[k] = v
+	}
+	res[token] = ct
+	return res
+}
+
+func (ct customToken) parser(token string) tagTokenParser {
+	return func(t *Tag, tt tagToken, o Options) error {
+		var ext interface{} = tt.value
+		if ct.parse != nil {
+			var err error
+			ext, err = ct.parse(tt.value, t, o)
+			if err != nil {
+				return err
+			}
+		}
+		return t.setExtension(token, ext)
+	}
+}
+
+// unmarshalHooks passes s through the Unmarshal hooks of the custom tokens
+// on f's tag, in the order they appear.
+func (f *field) unmarshalHooks(s string, o Options) (string, error) {
+	return f.runHooks(s, o, func(h TokenHook) TokenHookFunc { return h.Unmarshal })
+}
+
+// marshalHooks passes s through the Marshal hooks of the custom tokens on
+// f's tag, in the order they appear.
+func (f *field) marshalHooks(s string, o Options) (string, error) {
+	return f.runHooks(s, o, func(h TokenHook) TokenHookFunc { return h.Marshal })
+}
+
+func (f *field) runHooks(s string, o Options, hookFn func(h TokenHook) TokenHookFunc) (string, error) {
+	if f.tag == nil || len(f.tag.extensionTokens) == 0 {
+		return s, nil
+	}
+	for _, token := range f.tag.extensionTokens {
+		fn := hookFn(o.customTokens[token].hook)
+		if fn == nil {
+			continue
+		}
+		var err error
+		s, err = fn(s, f.tag.Extensions[token], *f.tag, o)
+		if err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,97 @@
+package labeler
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// scratch returns a deep copy of v, a pointer, along with a func which assigns
+// the copy to v. If v is not a non-nil pointer, it is returned as is so that
+// the usual errors are reported.
+func scratch(v interface{}) (interface{}, func()) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return v, func() {}
+	}
+	cp := reflect.New(rv.Elem().Type())
+	c := copier{seen: map[uintptr]reflect.Value{}}
+	c.copy(cp.Elem(), rv.Elem())
+	return cp.Interface(), func() {
+		rv.Elem().Set(cp.Elem())
+	}
+}
+
+// valueCopiers copy types whose unexported state would otherwise be shared
+// with the copy and mutated in place when set.
+var valueCopiers = map[reflect.Type]func(dst, src reflect.Value){
+	reflect.TypeOf(big.Int{}): func(dst, src reflect.Value) {
+		x := src.Interface().(big.Int)
+		dst.Addr().Interface().(*big.Int).Set(&x)
+	},
+	reflect.TypeOf(big.Float{}): func(dst, src reflect.Value) {
+		x := src.Interface().(big.Float)
+		dst.Addr().Interface().(*big.Float).Copy(&x)
+	},
+}
+
+// copier deep copies values, preserving pointers which are shared or cyclic.
+type copier struct {
+	seen map[uintptr]reflect.Value
+}
+
+// copy deep copies src into dst, which must be settable. Unexported fields are
+// copied as is.
+func (c copier) copy(dst, src reflect.Value) {
+	if fn, ok := valueCopiers[src.Type()]; ok {
+		fn(dst, src)
+		return
+	}
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		if p, ok := c.seen[src.Pointer()]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		c.seen[src.Pointer()] = p
+		c.copy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if df := dst.Field(i); df.CanSet() {
+				c.copy(df, src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.copy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			mv := reflect.New(src.Type().Elem()).Elem()
+			c.copy(mv, iter.Value())
+			m.SetMapIndex(iter.Key(), mv)
+		}
+		dst.Set(m)
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,112 @@
+package labeler
+
+import (
+	"reflect"
+	"strings"
+)
+
+// checkUnknownKeys reports each of keys which was not consumed by a field to
+// Options.UnknownKeyHandler, returning an UnknownKeysError if
+// Options.DisallowUnknownKeys is set.
+//
+// The fields of generated types are not consumed by labeler, so they are walked
+// and unmarshaled into a scratch value to determine which keys are known. Types
+// which otherwise implement Unmarshaler or UnmarshalerWithOpts are handed the
+// labels in their entirety and are not checked.
+func (sub *subject) checkUnknownKeys(keys []string, kvs *keyValues, o Options) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if sub.Implements(generatedType) {
+		walked, err := walkSubject(reflect.New(sub.Type()), o)
+		if err != nil {
+			return err
+		}
+		scratch := newKeyValues()
+		scratch.Add(kvs.Map())
+		// errors are reported by the generated UnmarshalLabels
+		_ = walked.unmarshalFields(&scratch, o)
+		return walked.reportUnknownKeys(keys, &scratch, o)
+	}
+	if sub.Implements(unmarshalerType) || sub.Implements(unmarshalerWithOptsType) {
+		return nil
+	}
+	return sub.reportUnknownKeys(keys, kvs, o)
+}
+
+func (sub *subject) reportUnknownKeys(keys []string, kvs *keyValues, o Options) error {
+	unknown := []UnknownKey{}
+	for _, key := range keys {
+		if kvs.IsUsed(key) {
+			continue
+		}
+		uk := UnknownKey{Key: key, Suggestion: sub.suggestKey(key, o)}
+		if o.UnknownKeyHandler != nil {
+			o.UnknownKeyHandler(uk)
+		}
+		unknown = append(unknown, uk)
+	}
+	if len(unknown) > 0 && o.DisallowUnknownKeys {
+		return &UnknownKeysError{Keys: unknown}
+	}
+	return nil
+}
+
+// suggestKey returns the key of sub's tagged fields closest to key by edit
+// distance, provided the distance is at most a third of key's length (or 1 for
+// short keys). Keys of prefixed maps are compared by their prefix.
+func (sub *subject) suggestKey(key string, o Options) string {
+	best, bestDist := "", len(key)/3
+	if bestDist < 1 {
+		bestDist = 1
+	}
+	for _, f := range sub.tagged {
+		known := f.key
+		cmp := key
+		if f.mapPrefix != "" {
+			known = f.mapPrefix
+			if len(cmp) > len(known) {
+				cmp = cmp[:len(known)]
+			}
+		}
+		if f.ignoreCase(o) {
+			known, cmp = strings.ToLower(known), strings.ToLower(cmp)
+		}
+		if d := editDistance(cmp, known); d <= bestDist && (best == "" || d < bestDist) {
+			best, bestDist = f.key, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -24,6 +24,7 @@ func getUnmarshal(r reflected, o Options) unmarshalFunc {
 }
 
 var fieldUnmarshalers = unmarshalerFuncs{
+	unmarshalConverter,
 	unmarshalSlice,
 	unmarshalArray,
 	unmarshalUnmarshalerWithOpts,
@@ -35,6 +36,7 @@ var fieldUnmarshalers = unmarshalerFuncs{
 }
 
 var collectionUnmarshalers = unmarshalerFuncs{
+	unmarshalConverter,
 	unmarshalFieldPkgString,
 	unmarshalFieldStringee,
 	unmarshalFieldTextUnmarshaler,
@@ -48,6 +50,7 @@ var containerUnmarshalers = unmarshalerFuncs{
 	unmarshalStrictLabelee,
 	unmarshalLabelee,
 	unmarshalMap,
+	unmarshalMultiMap,
 }
 
 var subjectUnmarshalers = unmarshalerFuncs{
@@ -87,6 +90,26 @@ var unmarshalMap = func(r reflected, o Options) unmarshalFunc {
 	return set.Unmarshaler(r, o)
 }
 
+var unmarshalMultiMap = func(r reflected, o Options) unmarshalFunc {
+	if r.Topic() != fieldTopic || !r.CanSet() || !r.Assignable(multiMapType) {
+		return nil
+	}
+	var set unmarshalFieldFunc = func(f *field, kvs *keyValues, o Options) error {
+		m := kvs.MultiMap()
+		if f.merges(o) && !f.value.IsNil() {
+			iter := f.value.MapRange()
+			for iter.Next() {
+				if _, ok := m[iter.Key().String()]; !ok {
+					m[iter.Key().String()] = iter.Value().Interface().([]string)
+				}
+			}
+		}
+		f.value.Set(reflect.ValueOf(m).Convert(f.typ))
+		return nil
+	}
+	return set.Unmarshaler(r, o)
+}
+
 var unmarshalUnmarshaler = func(r reflected, o Options) unmarshalFunc {
 	if !r.CanInterface() || !r.Implements(unmarshalerType) {
 		return nil
@@ -125,8 +148,7 @@ var unmarshalStrictLabelee = func(r reflected, o Options) unmarshalFunc {
 
 	return func(r reflected, kvs *keyValues, o Options) error {
 		u := r.Interface().(StrictLabelee)
-		u.SetLabels(kvs.Map())
-		return nil
+		return u.SetLabels(kvs.Map())
 	}
 }
 
@@ -135,9 +157,8 @@ var unmarshalGenericLabelee = func(r reflected, o Options) unmarshalFunc {
 		return nil
 	}
 	return func(r reflected, kvs *keyValues, o Options) error {
-		u := r.Interface().(Labelee)
-		u.SetLabels(kvs.Map())
-		return nil
+		u := r.Interface().(GenericLabelee)
+		return u.SetLabels(kvs.Map(), o.Tag)
 	}
 }
 
@@ -147,8 +168,7 @@ var unmarshalFieldStringee = func(r reflected, o Options) unmarshalFunc {
 	}
 	var fstr fieldStrUnmarshalFunc = func(f *field, s string, o Options) error {
 		u := f.Interface().(Stringee)
-		u.FromString(s)
-		return nil
+		return u.FromString(s)
 	}
 	return fstr.Unmarshaler(r, o)
 }
@@ -157,8 +177,8 @@ var unmarshalFieldTextUnmarshaler = func(r reflected, o Options) unmarshalFunc {
 	if !r.CanInterface() || !r.Implements(textUnmarshalerType) {
 		return nil
 	}
-	u := r.Interface().(TextUnmarshaler)
 	var set fieldStrUnmarshalFunc = func(f *field, s string, o Options) error {
+		u := f.Interface().(TextUnmarshaler)
 		return u.UnmarshalText([]byte(s))
 	}
 	return set.Unmarshaler(r, o)
@@ -186,9 +206,12 @@ var unmarshalFieldString = func(r reflected, o Options) unmarshalFunc {
 }
 
 func splitFieldValue(f *field, kvs *keyValues, o Options) ([]string, bool) {
-	kv, ok := kvs.Get(f.key, f.ignoreCase(o))
+	kv, ok := f.lookup(kvs, o)
 	var s string
 	switch {
+	case ok && len(kv.Values) > 1:
+		// multi-valued labels are not split
+		return kv.Values, true
 	case ok:
 		s = kv.Value
 	case f.HasDefault(o):
@@ -219,7 +242,7 @@ func unmarshalElem(f *field, rv reflect.Value, s string, fn unmarshalFunc, o Opt
 }
 
 var unmarshalArray = func(r reflected, o Options) unmarshalFunc {
-	if !r.IsArray() || r.ColType().Implements(stringeeType) || r.IsElem() {
+	if !r.IsArray() || r.ColType().Implements(stringeeType) || isPkgType(r.ColType()) || r.IsElem() {
 		return nil
 	}
 
@@ -241,7 +264,11 @@ var unmarshalArray = func(r reflected, o Options) unmarshalFunc {
 		f := r.(*field)
 		strs, hasVal := splitFieldValue(f, kvs, o)
 		if !hasVal {
-			return nil
+			return f.validateAbsent(o)
+		}
+		prev := f.snapshotCollection()
+		if !f.merges(o) {
+			f.ColValue().Set(reflect.Zero(f.colType))
 		}
 		for i, s := range strs {
 			if i >= f.len {
@@ -253,12 +280,12 @@ var unmarshalArray = func(r reflected, o Options) unmarshalFunc {
 			}
 		}
 
-		return nil
+		return f.validateUnmarshaledCollection(prev, strings.Join(strs, f.split(o)), o)
 	}
 }
 
 var unmarshalSlice = func(r reflected, o Options) unmarshalFunc {
-	if !r.IsSlice() || r.ColType().Implements(stringeeType) || r.IsElem() {
+	if !r.IsSlice() || r.ColType().Implements(stringeeType) || isPkgType(r.ColType()) || r.IsElem() {
 		return nil
 	}
 
@@ -276,17 +303,21 @@ var unmarshalSlice = func(r reflected, o Options) unmarshalFunc {
 		f := r.(*field)
 		strs, hasVal := splitFieldValue(f, kvs, o)
 		if !hasVal {
-			return nil
+			return f.validateAbsent(o)
+		}
+		prev := f.snapshotCollection()
+		if !f.merges(o) {
+			r.ColValue().Set(reflect.MakeSlice(f.colType, 0, len(strs)))
 		}
 		for _, s := range strs {
-			rv := reflect.New(r.Type()).Elem()
+			rv := reflect.New(r.ColElemType()).Elem()
 			if err := unmarshalElem(f, rv, s, fn, o); err != nil {
 				return err
 			}
-			r.ColValue().Set(reflect.Append(r.ColValue(), r.Value()))
+			r.ColValue().Set(reflect.Append(r.ColValue(), rv))
 		}
 
-		return nil
+		return f.validateUnmarshaledCollection(prev, strings.Join(strs, f.split(o)), o)
 	}
 }
 
@@ -298,7 +329,7 @@ func (setStr fieldStrUnmarshalFunc) Unmarshaler(r reflected, o Options) unmarsha
 	}
 	return func(r reflected, kvs *keyValues, o Options) error {
 		f := r.(*field)
-		kv, ok := kvs.Get(f.key, f.ignoreCase(o))
+		kv, ok := f.lookup(kvs, o)
 		var s string
 		switch {
 		case ok:
@@ -306,10 +337,35 @@ func (setStr fieldStrUnmarshalFunc) Unmarshaler(r reflected, o Options) unmarsha
 		case f.HasDefault(o):
 			s = f.Default(o)
 		case f.OmitEmpty(o):
-			return nil
+			return f.validateAbsent(o)
 		}
 		f.wasSet = true
-		return setStr(f, s, o)
+		f.raw = s
+		s, err := f.unmarshalHooks(s, o)
+		if err != nil {
+			return err
+		}
+		if f.isElem || !f.hasConstraints() {
+			if err := setStr(f, s, o); err != nil {
+				return err
+			}
+			return f.validate(s, o)
+		}
+		// the value is converted into a copy so that it is only assigned
+		// once it satisfies the constraints
+		rv := f.value
+		f.SetValue(reflect.New(rv.Type()).Elem())
+		err = setStr(f, s, o)
+		if err == nil {
+			err = f.validate(s, o)
+		}
+		converted := f.value
+		f.SetValue(rv)
+		if err != nil {
+			return err
+		}
+		rv.Set(converted)
+		return nil
 	}
 }
 
@@ -322,6 +378,32 @@ var fieldStringeePkgs = map[string]map[string]fieldStrUnmarshalFunc{
 			return f.setDuration(s, o)
 		},
 	},
+	"net": {
+		"IP": func(f *field, s string, o Options) error {
+			return f.setIP(s, o)
+		},
+		"IPNet": func(f *field, s string, o Options) error {
+			return f.setIPNet(s, o)
+		},
+	},
+	"net/url": {
+		"URL": func(f *field, s string, o Options) error {
+			return f.setURL(s, o)
+		},
+	},
+	"regexp": {
+		"Regexp": func(f *field, s string, o Options) error {
+			return f.setRegexp(s, o)
+		},
+	},
+	"math/big": {
+		"Int": func(f *field, s string, o Options) error {
+			return f.setBigInt(s, o)
+		},
+		"Float": func(f *field, s string, o Options) error {
+			return f.setBigFloat(s, o)
+		},
+	},
 }
 
 var fieldStringeeBasic = map[reflect.Kind]fieldStrUnmarshalFunc{
@@ -0,0 +1,279 @@
+package labeler
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is the Err of a FieldError when a field's value does not
+// satisfy a constraint (min, max, len, oneof, pattern, nonzero) set on its tag.
+type ValidationError struct {
+	// Path of the field
+	Path string
+	// Constraint is the token of the constraint that failed
+	Constraint string
+	// Param is the value assigned to the constraint, if any
+	Param string
+	// Value is the raw label value
+	Value string
+}
+
+// Error omits Path as ValidationErrors are wrapped by a FieldError, which
+// reports the field.
+func (err *ValidationError) Error() string {
+	if err.Param == "" {
+		return fmt.Sprintf("value %q does not satisfy %s", err.Value, err.Constraint)
+	}
+	return fmt.Sprintf("value %q does not satisfy %s:%s", err.Value, err.Constraint, err.Param)
+}
+
+func (err *ValidationError) Unwrap() error {
+	return ErrConstraint
+}
+
+// hasConstraints reports whether f's tag has any constraints.
+func (f *field) hasConstraints() bool {
+	t := f.tag
+	return t != nil && (t.Min != "" || t.Max != "" || t.Len != "" || t.OneOf != nil || t.Pattern != "" || t.NonZero)
+}
+
+// validate checks the value of f, converted from or to raw, against the
+// constraints of its tag. While f is an element of a collection only oneof
+// and pattern apply; the remaining constraints are checked against the
+// collection with validateCollection.
+func (f *field) validate(raw string, o Options) error {
+	if !f.hasConstraints() {
+		return nil
+	}
+	t := f.tag
+	if t.OneOf != nil && !containsString(t.OneOf, raw) {
+		return f.constraintErr(o.OneOfToken, strings.Join(t.OneOf, oneOfSeparator), raw)
+	}
+	if t.Pattern != "" && !t.pattern.MatchString(raw) {
+		return f.constraintErr(o.PatternToken, t.Pattern, raw)
+	}
+	if f.isElem {
+		return nil
+	}
+	return f.validateValue(f.value, raw, o)
+}
+
+// validateCollection checks the collection of f, which was converted from or
+// to raw, against the size constraints of its tag.
+func (f *field) validateCollection(raw string, o Options) error {
+	if !f.hasConstraints() {
+		return nil
+	}
+	return f.validateValue(f.colValue, raw, o)
+}
+
+// snapshotCollection returns a copy of the collection of f, prior to it being
+// unmarshaled, if f has constraints. Elements are copied shallowly.
+func (f *field) snapshotCollection() reflect.Value {
+	if !f.hasConstraints() {
+		return reflect.Value{}
+	}
+	col := f.colValue
+	prev := reflect.New(f.colType).Elem()
+	switch {
+	case col.Kind() == reflect.Slice && !col.IsNil():
+		prev.Set(reflect.MakeSlice(f.colType, col.Len(), col.Len()))
+		reflect.Copy(prev, col)
+	case col.Kind() == reflect.Map && !col.IsNil():
+		prev.Set(reflect.MakeMapWithSize(f.colType, col.Len()))
+		iter := col.MapRange()
+		for iter.Next() {
+			prev.SetMapIndex(iter.Key(), iter.Value())
+		}
+	default:
+		prev.Set(col)
+	}
+	return prev
+}
+
+// validateUnmarshaledCollection checks the collection of f as
+// validateCollection does, restoring prev, taken with snapshotCollection, if
+// the collection fails its constraints.
+func (f *field) validateUnmarshaledCollection(prev reflect.Value, raw string, o Options) error {
+	err := f.validateCollection(raw, o)
+	if err != nil && prev.IsValid() {
+		f.colValue.Set(prev)
+	}
+	return err
+}
+
+// parseBounds checks that the min and max bounds of f's tag can be compared
+// to f's type so that malformed bounds are reported when the field is parsed
+// rather than when it is unmarshaled.
+func (f *field) parseBounds(sf reflect.StructField) error {
+	if f.tag == nil || (f.tag.Min == "" && f.tag.Max == "") {
+		return nil
+	}
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	zero := reflect.New(t).Elem()
+	for _, bound := range []string{f.tag.Min, f.tag.Max} {
+		if bound == "" {
+			continue
+		}
+		if _, err := compareBound(zero, bound); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAbsent checks the current value of f, or its collection, against
+// the nonzero constraint when there is no label to unmarshal.
+func (f *field) validateAbsent(o Options) error {
+	if f.tag == nil || !f.tag.NonZero {
+		return nil
+	}
+	rv := f.value
+	if f.isElem {
+		rv = f.colValue
+	}
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.IsZero() {
+		return f.constraintErr(o.NonZeroToken, "", "")
+	}
+	return nil
+}
+
+func (f *field) validateValue(rv reflect.Value, raw string, o Options) error {
+	t := f.tag
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if t.NonZero && rv.IsZero() {
+		return f.constraintErr(o.NonZeroToken, "", raw)
+	}
+	if t.Len != "" {
+		if n, ok := length(rv); !ok || strconv.Itoa(n) != t.Len {
+			return f.constraintErr(o.LenToken, t.Len, raw)
+		}
+	}
+	if t.Min != "" {
+		c, err := compareBound(rv, t.Min)
+		if err != nil {
+			return err
+		}
+		if c < 0 {
+			return f.constraintErr(o.MinToken, t.Min, raw)
+		}
+	}
+	if t.Max != "" {
+		c, err := compareBound(rv, t.Max)
+		if err != nil {
+			return err
+		}
+		if c > 0 {
+			return f.constraintErr(o.MaxToken, t.Max, raw)
+		}
+	}
+	return nil
+}
+
+func (f *field) constraintErr(constraint, param, raw string) error {
+	return &ValidationError{
+		Path:       f.path,
+		Constraint: constraint,
+		Param:      param,
+		Value:      raw,
+	}
+}
+
+// length returns the length of strings (in runes), slices, arrays and maps.
+func length(rv reflect.Value) (int, bool) {
+	switch rv.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(rv.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), true
+	}
+	return 0, false
+}
+
+// compareBound compares rv to bound, returning -1, 0, or 1. Numbers are
+// compared by value (time.Duration accepts durations as the bound) while
+// strings, slices, arrays and maps are compared by length.
+func compareBound(rv reflect.Value, bound string) (int, error) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var b int64
+		var err error
+		if rv.Type() == durationType {
+			var d time.Duration
+			d, err = time.ParseDuration(bound)
+			b = int64(d)
+		} else {
+			b, err = strconv.ParseInt(bound, 10, 64)
+		}
+		if err != nil {
+			return 0, ErrMalformedTag
+		}
+		return compareInt(rv.Int(), b), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b, err := strconv.ParseUint(bound, 10, 64)
+		if err != nil {
+			return 0, ErrMalformedTag
+		}
+		v := rv.Uint()
+		switch {
+		case v < b:
+			return -1, nil
+		case v > b:
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.Float32, reflect.Float64:
+		b, err := strconv.ParseFloat(bound, 64)
+		if err != nil {
+			return 0, ErrMalformedTag
+		}
+		v := rv.Float()
+		switch {
+		case v < b:
+			return -1, nil
+		case v > b:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	n, ok := length(rv)
+	if !ok {
+		return 0, ErrUnsupportedType
+	}
+	b, err := strconv.Atoi(bound)
+	if err != nil {
+		return 0, ErrMalformedTag
+	}
+	return compareInt(int64(n), int64(b)), nil
+}
+
+func compareInt(v, b int64) int {
+	switch {
+	case v < b:
+		return -1
+	case v > b:
+		return 1
+	}
+	return 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}